@@ -0,0 +1,93 @@
+package polaris
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authenticator adds credentials to every request the client sends.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// NewAPIKeyAuthenticator authenticates with a Polaris API key using HTTP
+// Basic authentication, as Polaris expects for API keys.
+func NewAPIKeyAuthenticator(apiKey string) Authenticator {
+	return &apiKeyAuthenticator{apiKey: apiKey}
+}
+
+type apiKeyAuthenticator struct {
+	apiKey string
+}
+
+func (a *apiKeyAuthenticator) Authenticate(_ context.Context, req *http.Request) error {
+	auth := base64.StdEncoding.EncodeToString([]byte(a.apiKey + ":"))
+	req.Header.Set("Authorization", "Basic "+auth)
+	return nil
+}
+
+// NewBearerTokenAuthenticator authenticates with a pre-issued access token.
+func NewBearerTokenAuthenticator(token string) Authenticator {
+	return &bearerTokenAuthenticator{token: token}
+}
+
+type bearerTokenAuthenticator struct {
+	token string
+}
+
+func (a *bearerTokenAuthenticator) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// NewClientCredentialsAuthenticator authenticates with access tokens obtained
+// from tokenURL through the OAuth2 client credentials grant. Tokens are cached
+// and shared by every request made with the authenticator.
+func NewClientCredentialsAuthenticator(tokenURL, clientID, clientSecret string, httpClient *http.Client) Authenticator {
+	return &clientCredentialsAuthenticator{
+		tokens: newTokenManager(tokenURL, clientID, clientSecret, httpClient),
+	}
+}
+
+type clientCredentialsAuthenticator struct {
+	tokens *tokenManager
+}
+
+func (a *clientCredentialsAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate drops the token req was sent with so the next request fetches a
+// new one.
+func (a *clientCredentialsAuthenticator) Invalidate(req *http.Request) {
+	a.tokens.Invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+}
+
+// credentialInvalidator is implemented by authenticators whose credentials
+// can be revoked or expire early. When Polaris answers 401 the client calls
+// Invalidate with the rejected request and sends it once more.
+type credentialInvalidator interface {
+	Invalidate(req *http.Request)
+}
+
+// authenticationError marks failures to obtain credentials for a request.
+// They are reported as-is rather than retried.
+type authenticationError struct {
+	err error
+}
+
+func (e *authenticationError) Error() string {
+	return fmt.Sprintf("error authenticating request: %s", e.err)
+}
+
+func (e *authenticationError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,124 @@
+package polaris
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestProviderAuthenticator(t *testing.T) {
+	// Keep credentials from the environment out of the provider defaults.
+	for _, env := range []string{"POLARIS_API_KEY", "POLARIS_BEARER_TOKEN", "POLARIS_CLIENT_ID", "POLARIS_CLIENT_SECRET", "POLARIS_TOKEN_URL"} {
+		t.Setenv(env, "")
+	}
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    Authenticator
+		wantErr string
+	}{
+		{
+			name:   "api key",
+			config: map[string]interface{}{"api_key": "key"},
+			want:   &apiKeyAuthenticator{},
+		},
+		{
+			name:   "bearer token",
+			config: map[string]interface{}{"bearer_token": "token"},
+			want:   &bearerTokenAuthenticator{},
+		},
+		{
+			name:   "client credentials",
+			config: map[string]interface{}{"client_id": "id", "client_secret": "secret", "token_url": "https://auth.example.com/token"},
+			want:   &clientCredentialsAuthenticator{},
+		},
+		{
+			name:    "no credentials",
+			config:  map[string]interface{}{},
+			wantErr: "No Polaris credentials configured",
+		},
+		{
+			name:    "api key and bearer token",
+			config:  map[string]interface{}{"api_key": "key", "bearer_token": "token"},
+			wantErr: "Conflicting Polaris credentials configured",
+		},
+		{
+			name:    "api key and client credentials",
+			config:  map[string]interface{}{"api_key": "key", "client_id": "id", "client_secret": "secret"},
+			wantErr: "Conflicting Polaris credentials configured",
+		},
+		{
+			name:    "client_id without client_secret",
+			config:  map[string]interface{}{"client_id": "id", "token_url": "https://auth.example.com/token"},
+			wantErr: "OAuth client credentials require client_id, client_secret and token_url",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["base_url"] = "https://api.example.com"
+			d := schema.TestResourceDataRaw(t, Provider().Schema, tt.config)
+
+			auth, diags := providerAuthenticator(d)
+			if tt.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr) {
+					t.Fatalf("diagnostics = %#v, want an error containing %q", diags, tt.wantErr)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %#v", diags)
+			}
+			if reflect.TypeOf(auth) != reflect.TypeOf(tt.want) {
+				t.Errorf("authenticator = %T, want %T", auth, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorsSendAuthorization(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		auth Authenticator
+		want func(string) bool
+		desc string
+	}{
+		{
+			name: "api key",
+			auth: NewAPIKeyAuthenticator("key"),
+			want: func(h string) bool { return h == "Basic "+base64.StdEncoding.EncodeToString([]byte("key:")) },
+			desc: "Basic base64(key:)",
+		},
+		{
+			name: "bearer token",
+			auth: NewBearerTokenAuthenticator("token"),
+			want: func(h string) bool { return h == "Bearer token" },
+			desc: "Bearer token",
+		},
+		{
+			name: "client credentials",
+			auth: NewClientCredentialsAuthenticator(srv.TokenURL(), "id", "secret", nil),
+			want: func(h string) bool { return strings.HasPrefix(h, "Bearer fake-token-") },
+			desc: "Bearer with a token issued by the token endpoint",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(srv.URL, tt.auth, WithRetryPolicy(testRetryPolicy))
+			if _, err := client.ListTables(context.Background(), "p"); err != nil {
+				t.Fatalf("ListTables: %s", err)
+			}
+			if got := srv.LastRequestHeader().Get("Authorization"); !tt.want(got) {
+				t.Errorf("Authorization = %q, want %s", got, tt.desc)
+			}
+		})
+	}
+}
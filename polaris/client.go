@@ -2,222 +2,477 @@ package polaris
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+// Client talks to the Polaris v1 API. Every request goes through do, which is
+// the single place that handles authentication, JSON encoding and decoding,
+// status mapping and cancellation.
 type Client struct {
 	baseURL    string
-	apiKey     string
+	auth       Authenticator
 	httpClient *http.Client
+	retry      RetryPolicy
+	limiter    *requestLimiter
+
+	// requestTimeout bounds each individual HTTP attempt; zero means no
+	// limit beyond the caller's context.
+	requestTimeout time.Duration
+
+	// sensitiveLogKeys are masked in every log entry and logged payload.
+	sensitiveLogKeys []string
 }
 
-func NewClient(baseURL, apiKey string) *Client {
-	return &Client{
-		baseURL:    baseURL,
-		apiKey:     apiKey,
-		httpClient: &http.Client{},
+// ClientOption customises a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithRetryPolicy sets the policy used to retry transient failures.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
 	}
 }
 
-// Post sends a POST request to the Polaris API using Basic Authorization.
-func (client *Client) Post(url string, body interface{}) (*http.Response, error) {
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
+// WithRateLimit limits the client to requestsPerSecond with bursts of up to
+// burst requests, and allows at most maxMutations concurrent mutating
+// requests per project. Zero requestsPerSecond or maxMutations disables the
+// respective limit; zero burst allows one second's worth of requests.
+func WithRateLimit(requestsPerSecond float64, burst, maxMutations int) ClientOption {
+	return func(c *Client) {
+		c.limiter = newRequestLimiter(requestsPerSecond, burst, maxMutations)
 	}
+}
 
-	req, err := http.NewRequest("POST", client.baseURL+url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, err
+// WithRequestTimeout limits how long a single HTTP attempt may take. A timed
+// out attempt is retried like any other transient failure. Zero disables the
+// limit.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.requestTimeout = timeout
 	}
-	req.Header.Set("Content-Type", "application/json")
-	auth := base64.StdEncoding.EncodeToString([]byte(client.apiKey + ":"))
-	req.Header.Set("Authorization", "Basic "+auth)
+}
 
-	return client.httpClient.Do(req)
+// WithSensitiveLogKeys masks the values of the given fields and JSON keys in
+// the client's logs, in addition to the built-in defaults.
+func WithSensitiveLogKeys(keys ...string) ClientOption {
+	return func(c *Client) {
+		c.sensitiveLogKeys = append(c.sensitiveLogKeys, keys...)
+	}
 }
 
-func (client *Client) Get(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", client.baseURL+url, nil)
-	if err != nil {
-		return nil, err
+// WithHTTPClient replaces the underlying HTTP client.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
 	}
-	auth := base64.StdEncoding.EncodeToString([]byte(client.apiKey + ":"))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Basic "+auth)
+}
 
-	fmt.Printf("Request URL: %s\n", client.baseURL+url)
-	fmt.Printf("Authorization: Basic %s\n", auth)
+// NewClient returns a client for the Polaris API at baseURL that signs every
+// request with auth.
+func NewClient(baseURL string, auth Authenticator, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		auth:       auth,
+		httpClient: &http.Client{},
+		retry:      DefaultRetryPolicy(),
+		limiter:    newRequestLimiter(0, 0, 0),
 
-	return client.httpClient.Do(req)
+		sensitiveLogKeys: append([]string(nil), defaultSensitiveLogKeys...),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// Put sends a PUT request to the Polaris API.
-func (client *Client) Put(url string, body interface{}) (*http.Response, error) {
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
+// listResponse is the envelope Polaris wraps collection responses in.
+type listResponse struct {
+	Values json.RawMessage `json:"values"`
+}
+
+// ListTables returns every table in the project, following pagination.
+func (c *Client) ListTables(ctx context.Context, projectID string) ([]Table, error) {
+	var tables []Table
+	if err := c.list(ctx, tablesPath(projectID), &tables); err != nil {
 		return nil, err
 	}
+	return tables, nil
+}
 
-	req, err := http.NewRequest("PUT", client.baseURL+url, bytes.NewBuffer(jsonBody))
-	if err != nil {
+// GetTable returns the named table.
+func (c *Client) GetTable(ctx context.Context, projectID, name string) (*Table, error) {
+	var table Table
+	if err := c.do(ctx, http.MethodGet, tablePath(projectID, name), nil, &table); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+client.apiKey)
+	return &table, nil
+}
 
-	return client.httpClient.Do(req)
+// CreateTable creates table and returns it as stored by Polaris.
+func (c *Client) CreateTable(ctx context.Context, projectID string, table *Table) (*Table, error) {
+	var created Table
+	if err := c.do(ctx, http.MethodPost, tablesPath(projectID), table, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
 }
 
-func (c *Client) CreateTable(projectID string, table *Table) error {
-	url := fmt.Sprintf("%s/v1/projects/%s/tables", c.baseURL, projectID)
-	jsonData, err := json.Marshal(table)
-	if err != nil {
-		return fmt.Errorf("Error marshaling table: %s", err)
+// UpdateTable replaces the named table with table and returns the result.
+func (c *Client) UpdateTable(ctx context.Context, projectID, name string, table *Table) (*Table, error) {
+	var updated Table
+	if err := c.do(ctx, http.MethodPut, tablePath(projectID, name), table, &updated); err != nil {
+		return nil, err
 	}
+	return &updated, nil
+}
 
-	fmt.Printf("Request URL: %s\n", url)
-	fmt.Printf("Request Payload: %s\n", string(jsonData))
+// DeleteTable deletes the named table.
+func (c *Client) DeleteTable(ctx context.Context, projectID, name string) error {
+	return c.do(ctx, http.MethodDelete, tablePath(projectID, name), nil, nil)
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("Error creating request: %s", err)
+// ListConnections returns every connection in the project, following
+// pagination.
+func (c *Client) ListConnections(ctx context.Context, projectID string) ([]map[string]interface{}, error) {
+	var connections []map[string]interface{}
+	if err := c.list(ctx, connectionsPath(projectID), &connections); err != nil {
+		return nil, err
 	}
+	return connections, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	auth := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":"))
-	req.Header.Set("Authorization", "Basic "+auth)
-
-	fmt.Println("Headers set, sending request...")
+// GetConnection returns the named connection.
+func (c *Client) GetConnection(ctx context.Context, projectID, name string) (map[string]interface{}, error) {
+	var connection map[string]interface{}
+	if err := c.do(ctx, http.MethodGet, connectionPath(projectID, name), nil, &connection); err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("Error making request: %s", err)
+// CreateConnection creates connection and returns it as stored by Polaris.
+func (c *Client) CreateConnection(ctx context.Context, projectID string, connection map[string]interface{}) (map[string]interface{}, error) {
+	var created map[string]interface{}
+	if err := c.do(ctx, http.MethodPost, connectionsPath(projectID), connection, &created); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return created, nil
+}
 
-	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-	bodyString := string(bodyBytes)
+// UpdateConnection replaces the named connection with connection and
+// returns the result.
+func (c *Client) UpdateConnection(ctx context.Context, projectID, name string, connection map[string]interface{}) (map[string]interface{}, error) {
+	var updated map[string]interface{}
+	if err := c.do(ctx, http.MethodPut, connectionPath(projectID, name), connection, &updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
 
-	fmt.Printf("Response Status Code: %d\n", resp.StatusCode)
-	fmt.Printf("Response Body: %s\n", bodyString)
+// DeleteConnection deletes the named connection.
+func (c *Client) DeleteConnection(ctx context.Context, projectID, name string) error {
+	return c.do(ctx, http.MethodDelete, connectionPath(projectID, name), nil, nil)
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d, error: %s", resp.StatusCode, bodyString)
+// CreateJob submits a job and returns the ID Polaris assigned to it.
+func (c *Client) CreateJob(ctx context.Context, projectID string, job map[string]interface{}) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, jobsPath(projectID), job, &created); err != nil {
+		return "", err
 	}
+	if created.ID == "" {
+		return "", fmt.Errorf("job ID not found in response")
+	}
+	return created.ID, nil
+}
 
-	// Decode the response body into the table object to extract the ID
-	if err := json.NewDecoder(bytes.NewBuffer(bodyBytes)).Decode(table); err != nil {
-		return fmt.Errorf("Error decoding response=============================================: %s", err)
+// list fetches every page of a collection endpoint and decodes the combined
+// values into out. Pages are followed through the rel="next" Link header of
+// each response.
+func (c *Client) list(ctx context.Context, path string, out interface{}) error {
+	var values []json.RawMessage
+	seen := make(map[string]bool)
+	for page := path; page != ""; {
+		seen[page] = true
+
+		var resp listResponse
+		header, err := c.doRequest(ctx, http.MethodGet, page, nil, &resp)
+		if err != nil {
+			return err
+		}
+		if len(resp.Values) > 0 {
+			var pageValues []json.RawMessage
+			if err := json.Unmarshal(resp.Values, &pageValues); err != nil {
+				return fmt.Errorf("error decoding %s response: %w", page, err)
+			}
+			values = append(values, pageValues...)
+		}
+
+		next, err := c.nextPage(page, header)
+		if err != nil {
+			return err
+		}
+		if seen[next] {
+			break
+		}
+		page = next
 	}
 
-	// Ensure the table ID is set
-	if table.ID == "" {
-		return fmt.Errorf("Table ID not set in response===============================")
+	if len(values) == 0 {
+		return nil
+	}
+	combined, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("error decoding %s response: %w", path, err)
+	}
+	if err := json.Unmarshal(combined, out); err != nil {
+		return fmt.Errorf("error decoding %s response: %w", path, err)
 	}
-	log.Printf("[DEBUG] Created table with ID&£££££££££££££££££££££££££££££££££££££££££££££££££££: %s", table.ID)
-	fmt.Printf("Decoded Table ID£££££££££££££££££££££££££££££££££££££££££££££££££££: %s\n", table.ID)
 	return nil
 }
 
-func (client *Client) CreateConnection(projectID string, connection map[string]interface{}) error {
-	url := fmt.Sprintf("/v1/projects/%s/connections", projectID)
-	resp, err := client.Post(url, connection)
-	if err != nil {
-		return err
+// nextPage returns the path of the page following page, taken from the
+// rel="next" entry of the Link header, or "" on the last page. Links are
+// resolved against the request URL and must stay under the client's base URL
+// so credentials are never sent elsewhere.
+func (c *Client) nextPage(page string, header http.Header) (string, error) {
+	link := nextLink(header)
+	if link == "" {
+		return "", nil
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-	bodyString := string(bodyBytes)
-
-	fmt.Printf("Response Status Code: %d\n", resp.StatusCode)
-	fmt.Printf("Response Body: %s\n", bodyString)
+	current, err := url.Parse(c.baseURL + page)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s: %w", page, err)
+	}
+	next, err := current.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("error parsing next page link %q: %w", link, err)
+	}
+	path, ok := strings.CutPrefix(next.String(), c.baseURL)
+	if !ok || (path != "" && path[0] != '/' && path[0] != '?') {
+		return "", fmt.Errorf("next page link %q is outside %s", link, c.baseURL)
+	}
+	return path, nil
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d, error: %s", resp.StatusCode, bodyString)
+// nextLink extracts the target of the rel="next" entry of an RFC 8288 Link
+// header.
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, entry := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(entry, ";")
+			if !ok {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.EqualFold(name, "rel") && slices.Contains(strings.Fields(strings.Trim(rel, `"`)), "next") {
+					return strings.Trim(strings.TrimSpace(target), "<>")
+				}
+			}
+		}
 	}
+	return ""
+}
 
-	return nil
+// do sends a request to the Polaris API. When in is non-nil it is encoded as
+// the JSON request body; when out is non-nil the JSON response body is decoded
+// into it. Transient failures are retried according to the client's
+// RetryPolicy and every attempt is subject to the client's rate limit; any
+// non-2xx response is returned as an *APIError.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	_, err := c.doRequest(ctx, method, path, in, out)
+	return err
 }
 
-func (client *Client) UpdateConnection(url string, connection map[string]interface{}) error {
-	resp, err := client.Put(url, connection)
+// doRequest is do, additionally returning the headers of the successful
+// response.
+func (c *Client) doRequest(ctx context.Context, method, path string, in, out interface{}) (http.Header, error) {
+	ctx = clientLogContext(ctx, c.sensitiveLogKeys)
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s %s request: %w", method, path, err)
+		}
+		body = bytes.NewReader(payload)
+		tflog.SubsystemTrace(ctx, clientLogSubsystem, "Polaris request body", map[string]interface{}{
+			"method":       method,
+			"path":         path,
+			"request_body": redactPayload(payload, c.sensitiveLogKeys),
+		})
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error building %s %s request: %w", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code: %d, error: %s", resp.StatusCode, string(bodyBytes))
+	if isMutation(method) {
+		release, err := c.limiter.acquire(ctx, projectFromPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("error making %s %s request: %w", method, path, err)
+		}
+		defer release()
 	}
 
-	return nil
+	retries := 0
+	reauthenticated := false
+	for {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, fmt.Errorf("error making %s %s request: %w", method, path, err)
+		}
+
+		resp, respBody, err := c.send(req)
+		if err == nil && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+			err = parseErrorResponse(method, path, resp.StatusCode, respBody)
+		}
+
+		// A 401 may just mean the cached credentials went stale; refresh
+		// them and try once more without counting it as a retry.
+		if invalidator, ok := c.auth.(credentialInvalidator); ok && !reauthenticated && resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			tflog.SubsystemDebug(ctx, clientLogSubsystem, "Polaris request unauthorized, refreshing credentials", map[string]interface{}{
+				"method": method,
+				"path":   path,
+			})
+			invalidator.Invalidate(resp.Request)
+			reauthenticated = true
+			continue
+		}
+
+		if retries < c.retry.MaxRetries && shouldRetry(ctx, method, err) {
+			wait := c.retry.backoff(retries, resp)
+			retries++
+			tflog.SubsystemWarn(ctx, clientLogSubsystem, "Polaris request failed, retrying", map[string]interface{}{
+				"method":  method,
+				"path":    path,
+				"attempt": retries,
+				"wait":    wait.String(),
+				"error":   err.Error(),
+			})
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, fmt.Errorf("error making %s %s request: %w", method, path, err)
+			}
+			continue
+		}
+		switch err.(type) {
+		case *APIError, *authenticationError:
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error making %s %s request: %w", method, path, err)
+		}
+
+		if out == nil || len(bytes.TrimSpace(respBody)) == 0 {
+			return resp.Header, nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return nil, fmt.Errorf("error decoding %s %s response: %w", method, path, err)
+		}
+		return resp.Header, nil
+	}
 }
 
-func (client *Client) DeleteConnection(url string) error {
-	req, err := http.NewRequest("DELETE", client.baseURL+url, nil)
-	if err != nil {
-		return err
+// send performs a single attempt of req and returns the response together
+// with its fully read body. req itself is never sent, so it can be reused
+// across retries.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	ctx := req.Context()
+	attemptCtx := ctx
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+client.apiKey)
 
-	resp, err := client.httpClient.Do(req)
-	if err != nil {
-		return err
+	attempt := req.Clone(attemptCtx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		attempt.Body = body
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code: %d, error: %s", resp.StatusCode, string(bodyBytes))
+	if err := c.auth.Authenticate(req.Context(), attempt); err != nil {
+		return nil, nil, &authenticationError{err: err}
 	}
 
-	return nil
-}
+	tflog.SubsystemDebug(ctx, clientLogSubsystem, "Sending Polaris request", map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+	})
 
-func (client *Client) CreateJob(projectID string, job map[string]interface{}) (string, error) {
-	url := fmt.Sprintf("/v1/projects/%s/jobs", projectID)
-	resp, err := client.Post(url, job)
+	start := time.Now()
+	resp, err := c.httpClient.Do(attempt)
 	if err != nil {
-		return "", err
+		tflog.SubsystemDebug(ctx, clientLogSubsystem, "Polaris request failed", map[string]interface{}{
+			"method":   req.Method,
+			"path":     req.URL.Path,
+			"duration": time.Since(start).String(),
+			"error":    err.Error(),
+		})
+		if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return nil, nil, fmt.Errorf("request timed out after %s: %w", c.requestTimeout, err)
+		}
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-	bodyString := string(bodyBytes)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	fmt.Printf("Response Status Code: %d\n", resp.StatusCode)
-	fmt.Printf("Response Body: %s\n", bodyString)
+	tflog.SubsystemDebug(ctx, clientLogSubsystem, "Received Polaris response", map[string]interface{}{
+		"method":     req.Method,
+		"path":       req.URL.Path,
+		"status":     resp.StatusCode,
+		"duration":   time.Since(start).String(),
+		"request_id": resp.Header.Get("X-Request-Id"),
+	})
+	tflog.SubsystemTrace(ctx, clientLogSubsystem, "Polaris response body", map[string]interface{}{
+		"method":        req.Method,
+		"path":          req.URL.Path,
+		"response_body": redactPayload(respBody, c.sensitiveLogKeys),
+	})
+	return resp, respBody, nil
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("unexpected status code: %d, error: %s", resp.StatusCode, bodyString)
-	}
+func tablesPath(projectID string) string {
+	return fmt.Sprintf("/v1/projects/%s/tables", url.PathEscape(projectID))
+}
 
-	// Parse the response to extract the job ID
-	var result map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("error parsing response body: %s", err)
-	}
+func tablePath(projectID, name string) string {
+	return tablesPath(projectID) + "/" + url.PathEscape(name)
+}
 
-	jobID, ok := result["id"].(string)
-	if !ok {
-		return "", fmt.Errorf("job ID not found in response")
-	}
+func connectionsPath(projectID string) string {
+	return fmt.Sprintf("/v1/projects/%s/connections", url.PathEscape(projectID))
+}
 
-	return jobID, nil
+func connectionPath(projectID, name string) string {
+	return connectionsPath(projectID) + "/" + url.PathEscape(name)
 }
 
-// extractIDFromLocation extracts the table ID from the Location header.
-func extractIDFromLocation(location string) string {
-	parts := strings.Split(location, "/")
-	return parts[len(parts)-1]
+func jobsPath(projectID string) string {
+	return fmt.Sprintf("/v1/projects/%s/jobs", url.PathEscape(projectID))
 }
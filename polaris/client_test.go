@@ -0,0 +1,241 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+// testRetryPolicy retries quickly so tests do not wait on the default
+// backoff.
+var testRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: time.Millisecond,
+	MaxBackoff: 10 * time.Millisecond,
+}
+
+func TestClientRetriesTooManyRequestsAfterRetryAfter(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(polaristest.Fault{
+		Method:     http.MethodPost,
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: "1",
+		Count:      1,
+	})
+
+	retry := testRetryPolicy
+	retry.MaxBackoff = 2 * time.Second
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"), WithRetryPolicy(retry))
+	start := time.Now()
+	if _, err := client.CreateTable(context.Background(), "p", &Table{Name: "events", Type: "detail"}); err != nil {
+		t.Fatalf("CreateTable: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the 1s from Retry-After", elapsed)
+	}
+	if got := srv.RequestCount(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestClientCapsRetryAfterAtMaxBackoff(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(polaristest.Fault{
+		Method:     http.MethodPost,
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: "3600",
+		Count:      1,
+	})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"), WithRetryPolicy(testRetryPolicy))
+	start := time.Now()
+	if _, err := client.CreateTable(context.Background(), "p", &Table{Name: "events", Type: "detail"}); err != nil {
+		t.Fatalf("CreateTable: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retried after %s, want the Retry-After wait capped at %s", elapsed, testRetryPolicy.MaxBackoff)
+	}
+	if got := srv.RequestCount(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestClientDoesNotRetryPostOnServerError(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(polaristest.Fault{
+		Method:     http.MethodPost,
+		StatusCode: http.StatusInternalServerError,
+		Count:      1,
+	})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"), WithRetryPolicy(testRetryPolicy))
+	_, err := client.CreateTable(context.Background(), "p", &Table{Name: "events", Type: "detail"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("CreateTable error = %v, want a 500 APIError", err)
+	}
+	if got := srv.RequestCount(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestClientRetriesPostOnDialError(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	// Refuse the first two connections before Polaris could see the request.
+	var dials atomic.Int64
+	dialer := &net.Dialer{}
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if dials.Add(1) <= 2 {
+				return nil, &net.OpError{Op: "dial", Net: network, Err: syscall.ECONNREFUSED}
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"), WithRetryPolicy(testRetryPolicy), WithHTTPClient(httpClient))
+	if _, err := client.CreateTable(context.Background(), "p", &Table{Name: "events", Type: "detail"}); err != nil {
+		t.Fatalf("CreateTable: %s", err)
+	}
+	if got := dials.Load(); got != 3 {
+		t.Errorf("dialled %d times, want 3", got)
+	}
+	if got := srv.RequestCount(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestClientDoesNotRetryPostAfterConnectionDrop(t *testing.T) {
+	// The server reads the request and hangs up without answering, so
+	// Polaris may have acted on it.
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %s", err)
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"), WithRetryPolicy(testRetryPolicy))
+	if _, err := client.CreateTable(context.Background(), "p", &Table{Name: "events", Type: "detail"}); err == nil {
+		t.Fatal("CreateTable succeeded against a server that drops connections")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("POST sent %d times, want 1", got)
+	}
+
+	requests.Store(0)
+	if _, err := client.GetTable(context.Background(), "p", "events"); err == nil {
+		t.Fatal("GetTable succeeded against a server that drops connections")
+	}
+	if got, want := requests.Load(), int64(testRetryPolicy.MaxRetries+1); got != want {
+		t.Errorf("GET sent %d times, want %d", got, want)
+	}
+}
+
+func TestClientListFollowsLinkHeader(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.SetPageSize(2)
+	for i := 0; i < 5; i++ {
+		srv.PutTable("p", map[string]interface{}{"name": fmt.Sprintf("table%d", i), "type": "detail"})
+	}
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	tables, err := client.ListTables(context.Background(), "p")
+	if err != nil {
+		t.Fatalf("ListTables: %s", err)
+	}
+	if len(tables) != 5 {
+		t.Errorf("ListTables returned %d tables, want 5", len(tables))
+	}
+	if got := srv.RequestCount(); got != 3 {
+		t.Errorf("server saw %d requests, want 3 pages", got)
+	}
+}
+
+func TestClientNextPage(t *testing.T) {
+	client := NewClient("https://api.example.com", NewAPIKeyAuthenticator("key"))
+	tests := []struct {
+		link    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{`</v1/projects/p/tables?offset=2>; rel="next"`, "/v1/projects/p/tables?offset=2", false},
+		{`<?offset=4>; rel="next"`, "/v1/projects/p/tables?offset=4", false},
+		{`<https://api.example.com/v1/projects/p/tables?offset=6>; rel="next"`, "/v1/projects/p/tables?offset=6", false},
+		{`</v1/projects/p/tables?offset=0>; rel="prev"`, "", false},
+		{`<https://evil.example.com/steal>; rel="next"`, "", true},
+		{`<https://api.example.com.evil.example.com/>; rel="next"`, "", true},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.link != "" {
+			header.Set("Link", tt.link)
+		}
+		got, err := client.nextPage("/v1/projects/p/tables", header)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("nextPage(%q) = %q, %v, want %q, error %t", tt.link, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.SetLatency(500 * time.Millisecond)
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"),
+		WithRetryPolicy(testRetryPolicy),
+		WithRequestTimeout(20*time.Millisecond),
+	)
+
+	// Idempotent requests are retried after each timed out attempt.
+	start := time.Now()
+	_, err := client.GetTable(context.Background(), "p", "events")
+	if err == nil || !strings.Contains(err.Error(), "request timed out after 20ms") {
+		t.Fatalf("GetTable error = %v, want a request timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("GetTable took %s, want the attempts cancelled after 20ms each", elapsed)
+	}
+	if got, want := srv.RequestCount(), testRetryPolicy.MaxRetries+1; got != want {
+		t.Errorf("server saw %d requests, want %d", got, want)
+	}
+
+	// A POST that timed out may have been applied, so it is not retried.
+	before := srv.RequestCount()
+	_, err = client.CreateTable(context.Background(), "p", &Table{Name: "events", Type: "detail"})
+	if err == nil || !strings.Contains(err.Error(), "request timed out after 20ms") {
+		t.Fatalf("CreateTable error = %v, want a request timeout", err)
+	}
+	if got := srv.RequestCount() - before; got != 1 {
+		t.Errorf("server saw %d POST requests, want 1", got)
+	}
+
+	// Attempts that finish in time are unaffected.
+	srv.SetLatency(0)
+	if _, err := client.ListTables(context.Background(), "p"); err != nil {
+		t.Errorf("ListTables without latency: %s", err)
+	}
+}
@@ -0,0 +1,72 @@
+package polaris
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePolarisConnection() *schema.Resource {
+	s := dataSourceSchemaFromResourceSchema(resourcePolarisConnection().Schema)
+	s["project_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	s["name"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	// Secret values are write-only; only the secrets' type and identifiers
+	// such as usernames are exposed.
+	for _, connectionType := range connectionTypes {
+		block := s[connectionType].Elem.(*schema.Resource).Schema
+		secrets, ok := block["secrets"]
+		if !ok {
+			continue
+		}
+		secretsSchema := secrets.Elem.(*schema.Resource).Schema
+		for attr, attrSchema := range secretsSchema {
+			if attrSchema.Sensitive {
+				delete(secretsSchema, attr)
+			}
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourcePolarisConnectionRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+		Schema: s,
+	}
+}
+
+func dataSourcePolarisConnectionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID := d.Get("project_id").(string)
+	name := d.Get("name").(string)
+
+	connection, err := client.GetConnection(ctx, projectID, name)
+	if IsNotFound(err) {
+		return diag.Errorf("Connection %s not found in project %s", name, projectID)
+	}
+	if IsUnauthorized(err) {
+		return diag.Errorf("Unauthorized: Please check your API key and permissions: %s", err)
+	}
+	if err != nil {
+		return diag.Errorf("Error reading connection: %s", err)
+	}
+
+	if err := setConnectionState(d, connection); err != nil {
+		return diag.Errorf("Error reading connection: %s", err)
+	}
+	d.SetId(compositeID(projectID, name))
+
+	tflog.Debug(ctx, "Read connection data source", map[string]interface{}{
+		"project_id": projectID,
+		"name":       name,
+	})
+	return nil
+}
@@ -0,0 +1,68 @@
+package polaris
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestDataSourcePolarisConnectionHasNoSecrets(t *testing.T) {
+	var walk func(path string, s map[string]*schema.Schema)
+	walk = func(path string, s map[string]*schema.Schema) {
+		for name, attr := range s {
+			if attr.Sensitive {
+				t.Errorf("data source attribute %s%s is sensitive", path, name)
+			}
+			if elem, ok := attr.Elem.(*schema.Resource); ok {
+				walk(path+name+".", elem.Schema)
+			}
+		}
+	}
+	walk("", dataSourcePolarisConnection().Schema)
+}
+
+func TestDataSourcePolarisConnectionRead(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutConnection("p", map[string]interface{}{
+		"name":             "events",
+		"type":             "kafka",
+		"bootstrapServers": "broker:9092",
+		"topicName":        "events",
+		"secrets": map[string]interface{}{
+			"type":     "sasl_plain",
+			"username": "user",
+			"password": "secret-value",
+		},
+	})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	d := schema.TestResourceDataRaw(t, dataSourcePolarisConnection().Schema, map[string]interface{}{
+		"project_id": "p",
+		"name":       "events",
+	})
+	if diags := dataSourcePolarisConnectionRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	attrs := d.State().Attributes
+	for key, want := range map[string]string{
+		"id":                         "p/events",
+		"type":                       "kafka",
+		"kafka.0.bootstrap_servers":  "broker:9092",
+		"kafka.0.secrets.0.type":     "sasl_plain",
+		"kafka.0.secrets.0.username": "user",
+	} {
+		if attrs[key] != want {
+			t.Errorf("%s = %q, want %q", key, attrs[key], want)
+		}
+	}
+	for key, value := range attrs {
+		if strings.Contains(key, "password") || value == "secret-value" {
+			t.Errorf("data source exposes %s = %q", key, value)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package polaris
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourcePolarisConnections() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePolarisConnectionsRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(connectionTypes, false),
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"connections": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePolarisConnectionsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID := d.Get("project_id").(string)
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+	connectionType := d.Get("type").(string)
+
+	connections, err := client.ListConnections(ctx, projectID)
+	if IsUnauthorized(err) {
+		return diag.Errorf("Unauthorized: Please check your API key and permissions: %s", err)
+	}
+	if err != nil {
+		return diag.Errorf("Error listing connections: %s", err)
+	}
+
+	names := make([]string, 0, len(connections))
+	flatConnections := make([]interface{}, 0, len(connections))
+	for _, connection := range connections {
+		name, _ := connection["name"].(string)
+		if nameRegex != nil && !nameRegex.MatchString(name) {
+			continue
+		}
+		if connectionType != "" && connection["type"] != connectionType {
+			continue
+		}
+
+		names = append(names, name)
+		flatConnections = append(flatConnections, map[string]interface{}{
+			"name":        name,
+			"type":        connection["type"],
+			"description": connection["description"],
+		})
+	}
+
+	if err := d.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("connections", flatConnections); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(projectID)
+
+	tflog.Debug(ctx, "Listed connections", map[string]interface{}{
+		"project_id": projectID,
+		"count":      len(names),
+	})
+	return nil
+}
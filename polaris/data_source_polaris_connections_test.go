@@ -0,0 +1,58 @@
+package polaris
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestDataSourcePolarisConnectionsRead(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.SetPageSize(1)
+	srv.PutConnection("p", map[string]interface{}{"name": "clicks", "type": "kinesis", "description": "click stream"})
+	srv.PutConnection("p", map[string]interface{}{"name": "events", "type": "kafka"})
+	srv.PutConnection("p", map[string]interface{}{"name": "events_archive", "type": "s3"})
+	srv.PutConnection("p", map[string]interface{}{"name": "events_backup", "type": "s3"})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    []string
+	}{
+		{"all", nil, []string{"clicks", "events", "events_archive", "events_backup"}},
+		{"type", map[string]interface{}{"type": "s3"}, []string{"events_archive", "events_backup"}},
+		{"type and name_regex", map[string]interface{}{"type": "s3", "name_regex": "backup$"}, []string{"events_backup"}},
+		{"type without match", map[string]interface{}{"type": "gcs"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{"project_id": "p"}
+			for key, value := range tt.filters {
+				raw[key] = value
+			}
+			d := schema.TestResourceDataRaw(t, dataSourcePolarisConnections().Schema, raw)
+			if diags := dataSourcePolarisConnectionsRead(context.Background(), d, client); diags.HasError() {
+				t.Fatalf("read: %v", diags)
+			}
+
+			names := make([]string, 0)
+			for _, name := range d.Get("names").([]interface{}) {
+				names = append(names, name.(string))
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("names = %q, want %q", names, tt.want)
+			}
+			for i, raw := range d.Get("connections").([]interface{}) {
+				connection := raw.(map[string]interface{})
+				if filter, ok := tt.filters["type"]; ok && connection["type"] != filter {
+					t.Errorf("connections.%d.type = %q, want %q", i, connection["type"], filter)
+				}
+			}
+		})
+	}
+}
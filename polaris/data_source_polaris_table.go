@@ -0,0 +1,60 @@
+package polaris
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePolarisTable() *schema.Resource {
+	s := dataSourceSchemaFromResourceSchema(resourcePolarisTable().Schema)
+	s["project_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	s["name"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	// wait_for_availability only steers the resource's create and update.
+	delete(s, "wait_for_availability")
+
+	return &schema.Resource{
+		ReadContext: dataSourcePolarisTableRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+		Schema: s,
+	}
+}
+
+func dataSourcePolarisTableRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID := d.Get("project_id").(string)
+	tableName := d.Get("name").(string)
+
+	table, err := client.GetTable(ctx, projectID, tableName)
+	if IsNotFound(err) {
+		return diag.Errorf("Table %s not found in project %s", tableName, projectID)
+	}
+	if IsUnauthorized(err) {
+		return diag.Errorf("Unauthorized: Please check your API key and permissions: %s", err)
+	}
+	if err != nil {
+		return diag.Errorf("Error reading table: %s", err)
+	}
+
+	if err := setTableState(d, table); err != nil {
+		return diag.Errorf("Error reading table: %s", err)
+	}
+	d.SetId(compositeID(projectID, table.Name))
+
+	tflog.Debug(ctx, "Read table data source", map[string]interface{}{
+		"project_id": projectID,
+		"name":       tableName,
+		"table_id":   table.ID,
+	})
+	return nil
+}
@@ -0,0 +1,73 @@
+package polaris
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestDataSourcePolarisTableRead(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutTable("p", map[string]interface{}{
+		"name":         "events",
+		"type":         "detail",
+		"availability": "available",
+		"schema": []interface{}{
+			map[string]interface{}{"name": "__time", "dataType": "timestamp"},
+			map[string]interface{}{"name": "country", "dataType": "string"},
+		},
+		"segmentCompactedBytes": float64(1024),
+		"segmentTotalBytes":     float64(2048),
+		"totalDataSizeBytes":    float64(4096),
+		"totalRows":             float64(100),
+	})
+
+	if _, ok := dataSourcePolarisTable().Schema["wait_for_availability"]; ok {
+		t.Error("data source schema has wait_for_availability")
+	}
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	d := schema.TestResourceDataRaw(t, dataSourcePolarisTable().Schema, map[string]interface{}{
+		"project_id": "p",
+		"name":       "events",
+	})
+	if diags := dataSourcePolarisTableRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	attrs := d.State().Attributes
+	for key, want := range map[string]string{
+		"id":                      "p/events",
+		"type":                    "detail",
+		"availability":            "available",
+		"schema.#":                "2",
+		"schema.1.name":           "country",
+		"schema.1.data_type":      "string",
+		"queryable_schema.#":      "2",
+		"queryable_schema.0.name": "__time",
+		"segment_compacted_bytes": "1024",
+		"segment_total_bytes":     "2048",
+		"total_data_size_bytes":   "4096",
+		"total_rows":              "100",
+	} {
+		if attrs[key] != want {
+			t.Errorf("%s = %q, want %q", key, attrs[key], want)
+		}
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourcePolarisTable().Schema, map[string]interface{}{
+		"project_id": "p",
+		"name":       "missing",
+	})
+	diags := dataSourcePolarisTableRead(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "Table missing not found in project p") {
+		t.Errorf("reading a missing table = %#v, want a not found error", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("id = %q after reading a missing table, want none", d.Id())
+	}
+}
@@ -0,0 +1,170 @@
+package polaris
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourcePolarisTables() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePolarisTablesRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(tableTypes, false),
+			},
+			"availability": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"tables": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"table_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"availability": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"schema_mode": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_on_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"modified_on_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"segment_total_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"total_data_size_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"total_rows": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePolarisTablesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID := d.Get("project_id").(string)
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+	tableType := d.Get("type").(string)
+	availability := d.Get("availability").(string)
+
+	tables, err := client.ListTables(ctx, projectID)
+	if IsUnauthorized(err) {
+		return diag.Errorf("Unauthorized: Please check your API key and permissions: %s", err)
+	}
+	if err != nil {
+		return diag.Errorf("Error listing tables: %s", err)
+	}
+
+	names := make([]string, 0, len(tables))
+	flatTables := make([]interface{}, 0, len(tables))
+	for _, table := range tables {
+		if nameRegex != nil && !nameRegex.MatchString(table.Name) {
+			continue
+		}
+		if tableType != "" && table.Type != tableType {
+			continue
+		}
+		if availability != "" && table.Availability != availability {
+			continue
+		}
+
+		var description string
+		if table.Description != nil {
+			description = *table.Description
+		}
+		names = append(names, table.Name)
+		flatTables = append(flatTables, map[string]interface{}{
+			"name":                  table.Name,
+			"table_id":              table.ID,
+			"type":                  table.Type,
+			"description":           description,
+			"version":               table.Version,
+			"availability":          table.Availability,
+			"schema_mode":           table.SchemaMode,
+			"created_on_timestamp":  table.CreatedOnTimestamp,
+			"modified_on_timestamp": table.ModifiedOnTimestamp,
+			"segment_total_bytes":   table.SegmentTotalBytes,
+			"total_data_size_bytes": table.TotalDataSizeBytes,
+			"total_rows":            table.TotalRows,
+		})
+	}
+
+	if err := d.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("tables", flatTables); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(projectID)
+
+	tflog.Debug(ctx, "Listed tables", map[string]interface{}{
+		"project_id": projectID,
+		"count":      len(names),
+	})
+	return nil
+}
@@ -0,0 +1,87 @@
+package polaris
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestDataSourcePolarisTablesRead(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	// One table per page, so every listing follows the Link header.
+	srv.SetPageSize(1)
+	srv.PutTable("p", map[string]interface{}{"name": "events", "type": "detail"})
+	srv.PutTable("p", map[string]interface{}{"name": "events_rollup", "type": "aggregate"})
+	srv.PutTable("p", map[string]interface{}{"name": "events_staging", "type": "detail", "availability": "unavailable"})
+	srv.PutTable("p", map[string]interface{}{"name": "clicks", "type": "detail", "description": "raw clicks"})
+	srv.PutTable("other", map[string]interface{}{"name": "events_other", "type": "detail"})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    []string
+	}{
+		{"all", nil, []string{"clicks", "events", "events_rollup", "events_staging"}},
+		{"name_regex", map[string]interface{}{"name_regex": "^events_"}, []string{"events_rollup", "events_staging"}},
+		{"type", map[string]interface{}{"type": "aggregate"}, []string{"events_rollup"}},
+		{"availability", map[string]interface{}{"availability": "unavailable"}, []string{"events_staging"}},
+		{"combined", map[string]interface{}{"name_regex": "^events", "type": "detail", "availability": "available"}, []string{"events"}},
+		{"no match", map[string]interface{}{"name_regex": "^orders"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{"project_id": "p"}
+			for key, value := range tt.filters {
+				raw[key] = value
+			}
+			d := schema.TestResourceDataRaw(t, dataSourcePolarisTables().Schema, raw)
+			if diags := dataSourcePolarisTablesRead(context.Background(), d, client); diags.HasError() {
+				t.Fatalf("read: %v", diags)
+			}
+
+			names := make([]string, 0)
+			for _, name := range d.Get("names").([]interface{}) {
+				names = append(names, name.(string))
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("names = %q, want %q", names, tt.want)
+			}
+			tables := d.Get("tables").([]interface{})
+			if len(tables) != len(tt.want) {
+				t.Fatalf("got %d tables, want %d", len(tables), len(tt.want))
+			}
+			for i, raw := range tables {
+				if got := raw.(map[string]interface{})["name"]; got != tt.want[i] {
+					t.Errorf("tables.%d.name = %q, want %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if got, want := srv.RequestCount(), 4*len(tests); got < want {
+		t.Errorf("server saw %d requests, want at least %d pages", got, want)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourcePolarisTables().Schema, map[string]interface{}{"project_id": "p", "name_regex": "^clicks$"})
+	if diags := dataSourcePolarisTablesRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if d.Id() != "p" {
+		t.Errorf("id = %q, want p", d.Id())
+	}
+	for key, want := range map[string]interface{}{
+		"tables.0.type":         "detail",
+		"tables.0.description":  "raw clicks",
+		"tables.0.availability": "available",
+		"tables.0.schema_mode":  "flexible",
+	} {
+		if got := d.Get(key); got != want {
+			t.Errorf("%s = %#v, want %#v", key, got, want)
+		}
+	}
+}
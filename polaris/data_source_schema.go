@@ -0,0 +1,28 @@
+package polaris
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceSchemaFromResourceSchema copies a resource schema with every
+// attribute, nested ones included, turned into a computed attribute, so a data
+// source exposes exactly what the resource does. Callers mark the attributes
+// used for the lookup as required afterwards.
+func dataSourceSchemaFromResourceSchema(rs map[string]*schema.Schema) map[string]*schema.Schema {
+	ds := make(map[string]*schema.Schema, len(rs))
+	for name, attr := range rs {
+		computed := &schema.Schema{
+			Type:      attr.Type,
+			Computed:  true,
+			Sensitive: attr.Sensitive,
+		}
+		switch elem := attr.Elem.(type) {
+		case *schema.Resource:
+			computed.Elem = &schema.Resource{Schema: dataSourceSchemaFromResourceSchema(elem.Schema)}
+		case *schema.Schema:
+			computed.Elem = &schema.Schema{Type: elem.Type}
+		}
+		ds[name] = computed
+	}
+	return ds
+}
@@ -0,0 +1,147 @@
+package polaris
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// apiErrorDiagnostics converts err into diagnostics under summary. When err
+// is an APIError whose details carry a target such as "schema[3].dataType",
+// each detail becomes its own diagnostic pointing at the matching attribute
+// of resourceSchema (schema.3.data_type), so Terraform highlights the
+// offending block in the configuration.
+func apiErrorDiagnostics(summary string, err error, resourceSchema map[string]*schema.Schema) diag.Diagnostics {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || (len(apiErr.Details) == 0 && apiErr.Target == "") {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   err.Error(),
+		}}
+	}
+
+	if len(apiErr.Details) == 0 {
+		return diag.Diagnostics{targetDiagnostic(summary, apiErr.Message, apiErr.Target, resourceSchema)}
+	}
+
+	var diags diag.Diagnostics
+	for _, detail := range apiErr.Details {
+		message := detail.Message
+		if message == "" {
+			message = apiErr.Message
+		}
+		target := detail.Target
+		if target == "" {
+			target = apiErr.Target
+		}
+		diags = append(diags, targetDiagnostic(summary, message, target, resourceSchema))
+	}
+	return diags
+}
+
+func targetDiagnostic(summary, message, target string, resourceSchema map[string]*schema.Schema) diag.Diagnostic {
+	d := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  summary,
+		Detail:   message,
+	}
+	if target == "" {
+		return d
+	}
+
+	path := targetAttributePath(target, resourceSchema)
+	if len(path) == 0 {
+		d.Detail = fmt.Sprintf("%s: %s", target, message)
+		return d
+	}
+	d.AttributePath = path
+	return d
+}
+
+// targetAttributePath translates a Polaris error target into the attribute
+// path of the matching field in resourceSchema. Segments are converted from
+// camelCase to snake_case, and single-item nested blocks get the implicit
+// ".0" index Terraform uses for them. Translation stops at the first segment
+// the schema does not know about, so the path points at the closest known
+// attribute; an empty path means nothing matched.
+func targetAttributePath(target string, resourceSchema map[string]*schema.Schema) cty.Path {
+	var path cty.Path
+	current := resourceSchema
+
+	for _, segment := range strings.Split(strings.TrimPrefix(target, "$."), ".") {
+		if current == nil {
+			break
+		}
+
+		name, indexes := parseTargetSegment(segment)
+		attr, ok := current[toSnakeCase(name)]
+		if !ok {
+			break
+		}
+		path = path.GetAttr(toSnakeCase(name))
+		current = nil
+
+		if attr.Type != schema.TypeList {
+			break
+		}
+		if len(indexes) == 0 && attr.MaxItems == 1 {
+			indexes = []int{0}
+		}
+		for _, index := range indexes {
+			path = path.IndexInt(index)
+		}
+		if elem, ok := attr.Elem.(*schema.Resource); ok && len(indexes) > 0 {
+			current = elem.Schema
+		}
+	}
+
+	return path
+}
+
+// parseTargetSegment splits a target segment such as "schema[3]" into its
+// field name and list indexes.
+func parseTargetSegment(segment string) (string, []int) {
+	open := strings.IndexByte(segment, '[')
+	if open < 0 {
+		return segment, nil
+	}
+
+	name := segment[:open]
+	var indexes []int
+	for _, part := range strings.Split(segment[open:], "]") {
+		part = strings.TrimPrefix(part, "[")
+		if part == "" {
+			continue
+		}
+		index, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		indexes = append(indexes, index)
+	}
+	return name, indexes
+}
+
+// toSnakeCase converts a Polaris camelCase field name to the snake_case name
+// used by the provider schema.
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
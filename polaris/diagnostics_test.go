@@ -0,0 +1,127 @@
+package polaris
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestTargetAttributePath(t *testing.T) {
+	tableSchema := resourcePolarisTable().Schema
+	tests := []struct {
+		target string
+		want   cty.Path
+	}{
+		{"schema[3].dataType", cty.GetAttrPath("schema").IndexInt(3).GetAttr("data_type")},
+		{"$.schema[0].name", cty.GetAttrPath("schema").IndexInt(0).GetAttr("name")},
+		{"partitioningGranularity", cty.GetAttrPath("partitioning_granularity")},
+		{"queryGranularity.timeZone", cty.GetAttrPath("query_granularity").IndexInt(0).GetAttr("time_zone")},
+		{"storagePolicy.retain.period", cty.GetAttrPath("storage_policy").IndexInt(0).GetAttr("retain").IndexInt(0).GetAttr("period")},
+		{"schema[1].unknownField", cty.GetAttrPath("schema").IndexInt(1)},
+		{"schema.dataType", cty.GetAttrPath("schema")},
+		{"description.length", cty.GetAttrPath("description")},
+		{"unknownField", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := targetAttributePath(tt.target, tableSchema); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("targetAttributePath(%q) = %#v, want %#v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestParseTargetSegment(t *testing.T) {
+	tests := []struct {
+		segment     string
+		wantName    string
+		wantIndexes []int
+	}{
+		{"schema", "schema", nil},
+		{"schema[3]", "schema", []int{3}},
+		{"matrix[1][2]", "matrix", []int{1, 2}},
+		{"schema[x]", "schema", nil},
+		{"schema[]", "schema", nil},
+	}
+	for _, tt := range tests {
+		name, indexes := parseTargetSegment(tt.segment)
+		if name != tt.wantName || !reflect.DeepEqual(indexes, tt.wantIndexes) {
+			t.Errorf("parseTargetSegment(%q) = %q, %v, want %q, %v", tt.segment, name, indexes, tt.wantName, tt.wantIndexes)
+		}
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"name":              "name",
+		"dataType":          "data_type",
+		"bootstrapServers":  "bootstrap_servers",
+		"awsAssumedRoleArn": "aws_assumed_role_arn",
+		"lgK":               "lg_k",
+		"sasToken":          "sas_token",
+	}
+	for in, want := range tests {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAPIErrorDiagnostics(t *testing.T) {
+	tableSchema := resourcePolarisTable().Schema
+
+	err := &APIError{
+		StatusCode: http.StatusBadRequest,
+		Message:    "invalid table",
+		Details: []ErrorResponseDetail{
+			{Message: "unknown data type", Target: "schema[3].dataType"},
+			{Message: "bad column", Target: "columns[0]"},
+			{Target: "partitioningGranularity"},
+		},
+	}
+	diags := apiErrorDiagnostics("Error creating table", err, tableSchema)
+	if len(diags) != 3 {
+		t.Fatalf("got %d diagnostics, want 3: %#v", len(diags), diags)
+	}
+	if want := cty.GetAttrPath("schema").IndexInt(3).GetAttr("data_type"); !reflect.DeepEqual(diags[0].AttributePath, want) {
+		t.Errorf("diagnostic 0 path = %#v, want %#v", diags[0].AttributePath, want)
+	}
+	if diags[1].AttributePath != nil || diags[1].Detail != "columns[0]: bad column" {
+		t.Errorf("diagnostic 1 = %#v, want the target in the detail", diags[1])
+	}
+	if diags[2].Detail != "invalid table" {
+		t.Errorf("diagnostic 2 detail = %q, want the top-level message", diags[2].Detail)
+	}
+
+	diags = apiErrorDiagnostics("Error creating table", errors.New("connection refused"), tableSchema)
+	if len(diags) != 1 || diags[0].Detail != "connection refused" || diags[0].AttributePath != nil {
+		t.Errorf("plain error diagnostics = %#v", diags)
+	}
+}
+
+func TestConnectionErrorDiagnostics(t *testing.T) {
+	tests := []struct {
+		connectionType string
+		target         string
+		want           cty.Path
+	}{
+		{"kafka", "bootstrapServers", cty.GetAttrPath("kafka").IndexInt(0).GetAttr("bootstrap_servers")},
+		{"kafka", "$.topicName", cty.GetAttrPath("kafka").IndexInt(0).GetAttr("topic_name")},
+		{"kafka", "secrets.password", cty.GetAttrPath("kafka").IndexInt(0).GetAttr("secrets").IndexInt(0).GetAttr("password")},
+		{"kafka", "description", cty.GetAttrPath("description")},
+		{"kinesis", "stream", cty.GetAttrPath("kinesis").IndexInt(0).GetAttr("stream")},
+		{"kinesis", "bootstrapServers", nil},
+	}
+	for _, tt := range tests {
+		err := &APIError{StatusCode: http.StatusBadRequest, Message: "invalid", Target: tt.target}
+		diags := connectionErrorDiagnostics("Error creating connection", err, tt.connectionType)
+		if len(diags) != 1 {
+			t.Fatalf("%s %q: got %d diagnostics, want 1", tt.connectionType, tt.target, len(diags))
+		}
+		if got := diags[0].AttributePath; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q: path = %#v, want %#v", tt.connectionType, tt.target, got, tt.want)
+		}
+	}
+}
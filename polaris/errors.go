@@ -0,0 +1,97 @@
+package polaris
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by APIError.Is, so callers can classify failures
+// with errors.Is instead of inspecting status codes or messages.
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrConflict     = errors.New("resource conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("validation failed")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// APIError is returned for every non-2xx response from Polaris. It carries
+// the decoded ErrorResponse body alongside the HTTP status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+
+	Code       string
+	Message    string
+	Target     string
+	Details    []ErrorResponseDetail
+	InnerError *InnerError
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %d", e.Method, e.Path, e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " %s", e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	for _, detail := range e.Details {
+		if detail.Target != "" {
+			fmt.Fprintf(&b, "\n - %s (%s): %s", detail.Code, detail.Target, detail.Message)
+		} else {
+			fmt.Fprintf(&b, "\n - %s: %s", detail.Code, detail.Message)
+		}
+	}
+	if e.InnerError != nil {
+		fmt.Fprintf(&b, "\nInner Error: %s - %s", e.InnerError.Code, e.InnerError.Message)
+	}
+	return b.String()
+}
+
+// Is maps the response status onto the package's sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsNotFound reports whether err is a Polaris 404 response.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err is a Polaris 409 response, typically because
+// the resource already exists.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsUnauthorized reports whether err is a Polaris 401 or 403 response.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsValidationError reports whether Polaris rejected the request body.
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+// IsRateLimited reports whether err is a Polaris 429 response.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
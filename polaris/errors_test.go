@@ -0,0 +1,47 @@
+package polaris
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{ErrNotFound, ErrConflict, ErrUnauthorized, ErrValidation, ErrRateLimited}
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, nil},
+	}
+	for _, tt := range tests {
+		// Wrapping must not hide the classification.
+		err := fmt.Errorf("reading table: %w", &APIError{StatusCode: tt.status})
+		for _, sentinel := range sentinels {
+			if got, want := errors.Is(err, sentinel), sentinel == tt.want; got != want {
+				t.Errorf("errors.Is(%d, %q) = %t, want %t", tt.status, sentinel, got, want)
+			}
+		}
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	err := &APIError{StatusCode: http.StatusForbidden}
+	if !IsUnauthorized(err) {
+		t.Error("IsUnauthorized(403) = false")
+	}
+	if IsNotFound(err) || IsConflict(err) || IsValidationError(err) || IsRateLimited(err) {
+		t.Error("403 classified as another error")
+	}
+	if IsNotFound(errors.New("not found")) {
+		t.Error("IsNotFound accepts a plain error")
+	}
+}
@@ -0,0 +1,131 @@
+package polaris
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// clientLogSubsystem is the tflog subsystem the API client logs to. Its level
+// can be set independently with TF_LOG_PROVIDER_POLARIS_CLIENT.
+const clientLogSubsystem = "polaris_client"
+
+// redactedValue replaces sensitive values in logged payloads.
+const redactedValue = "***"
+
+// defaultSensitiveLogKeys are header names and JSON keys whose values are
+// never logged, whatever the provider schema declares.
+var defaultSensitiveLogKeys = []string{
+	"Authorization",
+	"password",
+	"secrets",
+	"client_secret",
+	"clientSecret",
+	"access_token",
+	"accessToken",
+}
+
+// clientLogContext returns ctx with the polaris_client subsystem configured
+// to mask the given sensitive fields.
+func clientLogContext(ctx context.Context, sensitiveKeys []string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, clientLogSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_POLARIS_CLIENT"))
+	return tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, clientLogSubsystem, sensitiveKeys...)
+}
+
+// redactPayload renders a JSON request or response body for logging, with the
+// values of sensitive keys replaced. Bodies that are not JSON are not logged
+// verbatim since they cannot be redacted reliably.
+func redactPayload(body []byte, sensitiveKeys []string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "<non-JSON body omitted>"
+	}
+
+	sensitive := make(map[string]bool, len(sensitiveKeys))
+	for _, key := range sensitiveKeys {
+		sensitive[strings.ToLower(key)] = true
+	}
+
+	redacted, err := json.Marshal(redactValue(payload, sensitive))
+	if err != nil {
+		return "<unprintable body omitted>"
+	}
+	return string(redacted)
+}
+
+func redactValue(v interface{}, sensitive map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if sensitive[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(value, sensitive)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value, sensitive)
+		}
+	}
+	return v
+}
+
+// sensitiveSchemaKeys collects the names of every attribute marked Sensitive
+// in the provider, resource and data source schemas, in both their snake_case
+// and camelCase (API) spellings.
+func sensitiveSchemaKeys(p *schema.Provider) []string {
+	seen := make(map[string]bool)
+	collectSensitiveKeys(p.Schema, seen)
+	for _, r := range p.ResourcesMap {
+		collectSensitiveKeys(r.Schema, seen)
+	}
+	for _, r := range p.DataSourcesMap {
+		collectSensitiveKeys(r.Schema, seen)
+	}
+
+	for _, key := range defaultSensitiveLogKeys {
+		seen[key] = true
+	}
+	for key := range seen {
+		seen[toCamelCase(key)] = true
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func collectSensitiveKeys(s map[string]*schema.Schema, seen map[string]bool) {
+	for name, attr := range s {
+		if attr.Sensitive {
+			seen[name] = true
+		}
+		if elem, ok := attr.Elem.(*schema.Resource); ok {
+			collectSensitiveKeys(elem.Schema, seen)
+		}
+	}
+}
+
+// toCamelCase converts a snake_case schema attribute name to the camelCase
+// spelling the Polaris API uses.
+func toCamelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,126 @@
+package polaris
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestClientLogsRedactSecrets(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_POLARIS_CLIENT", "TRACE")
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	secrets := []string{
+		"bearer-token-value",
+		"password-value",
+		"sas-token-value",
+		"api-secret-value",
+		"client-secret-value",
+		"account-key-value",
+	}
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	client := NewClient(srv.URL, NewBearerTokenAuthenticator("bearer-token-value"),
+		WithSensitiveLogKeys(sensitiveSchemaKeys(Provider())...))
+	_, err := client.CreateConnection(ctx, "p", map[string]interface{}{
+		"name":      "events",
+		"type":      "kafka",
+		"topicName": "events",
+		"secrets": map[string]interface{}{
+			"type":     "sasl_plain",
+			"username": "user",
+			"password": "password-value",
+		},
+		// Sensitive fields are masked wherever they appear in the payload.
+		"credentials": []interface{}{map[string]interface{}{
+			"sasToken":     "sas-token-value",
+			"apiSecret":    "api-secret-value",
+			"clientSecret": "client-secret-value",
+			"key":          "account-key-value",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateConnection: %s", err)
+	}
+
+	logs := output.String()
+	if !strings.Contains(logs, "Polaris request body") || !strings.Contains(logs, "topicName") {
+		t.Fatalf("request body was not logged:\n%s", logs)
+	}
+	for _, secret := range secrets {
+		if strings.Contains(logs, secret) {
+			t.Errorf("logs contain %q:\n%s", secret, logs)
+		}
+	}
+}
+
+func TestRedactPayload(t *testing.T) {
+	keys := []string{"password", "sasToken"}
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"", ""},
+		{`not json`, "<non-JSON body omitted>"},
+		{`{"name": "a", "password": "x"}`, `{"name":"a","password":"***"}`},
+		{`{"SASTOKEN": {"nested": "x"}}`, `{"SASTOKEN":"***"}`},
+		{`[{"values": [{"password": "x"}]}]`, `[{"values":[{"password":"***"}]}]`},
+	}
+	for _, tt := range tests {
+		if got := redactPayload([]byte(tt.body), keys); got != tt.want {
+			t.Errorf("redactPayload(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestSensitiveSchemaKeys(t *testing.T) {
+	p := Provider()
+	keys := sensitiveSchemaKeys(p)
+
+	var walk func(s map[string]*schema.Schema)
+	walk = func(s map[string]*schema.Schema) {
+		for name, attr := range s {
+			if attr.Sensitive {
+				for _, key := range []string{name, toCamelCase(name)} {
+					if !slices.Contains(keys, key) {
+						t.Errorf("sensitive attribute %s: %q is not masked", name, key)
+					}
+				}
+			}
+			if elem, ok := attr.Elem.(*schema.Resource); ok {
+				walk(elem.Schema)
+			}
+		}
+	}
+	walk(p.Schema)
+	for _, r := range p.ResourcesMap {
+		walk(r.Schema)
+	}
+
+	for _, key := range []string{"password", "sasToken", "apiSecret", "clientSecret", "sasKey", "connectionString", "apiKey", "bearerToken", "Authorization"} {
+		if !slices.Contains(keys, key) {
+			t.Errorf("%q is not masked", key)
+		}
+	}
+}
+
+func TestToCamelCase(t *testing.T) {
+	tests := map[string]string{
+		"key":                  "key",
+		"sas_token":            "sasToken",
+		"client_secret":        "clientSecret",
+		"aws_assumed_role_arn": "awsAssumedRoleArn",
+	}
+	for in, want := range tests {
+		if got := toCamelCase(in); got != want {
+			t.Errorf("toCamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
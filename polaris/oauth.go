@@ -0,0 +1,195 @@
+package polaris
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// tokenRefreshWindow is how long before expiry a cached token is
+	// replaced, so requests never go out with a token about to expire. For
+	// short-lived tokens the window shrinks to a quarter of their lifetime.
+	tokenRefreshWindow = time.Minute
+
+	// defaultTokenLifetime is assumed for tokens whose response omits
+	// expires_in, which RFC 6749 makes optional.
+	defaultTokenLifetime = time.Hour
+
+	// tokenRequestTimeout bounds a single call to the token endpoint.
+	tokenRequestTimeout = 30 * time.Second
+)
+
+// tokenManager obtains OAuth2 access tokens with the client credentials grant
+// and caches them for every resource sharing the provider's Client. When the
+// token needs refreshing, concurrent callers wait on a single request to the
+// token endpoint instead of each fetching their own.
+type tokenManager struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	pending   *tokenRefresh
+}
+
+// tokenRefresh is an in-flight request to the token endpoint. done is closed
+// once token and err are set.
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newTokenManager(tokenURL, clientID, clientSecret string, httpClient *http.Client) *tokenManager {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &tokenManager{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   httpClient,
+	}
+}
+
+// Token returns a valid access token, fetching a new one when the cached
+// token is missing or within its refresh window of expiring.
+func (m *tokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	if m.token != "" && time.Now().Before(m.refreshAt) {
+		token := m.token
+		m.mu.Unlock()
+		return token, nil
+	}
+	refresh := m.pending
+	if refresh == nil {
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		m.pending = refresh
+		// The refresh is shared, so it must not be cancelled along with
+		// the context of whichever caller happened to start it.
+		go m.refresh(context.WithoutCancel(ctx), refresh)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.token, refresh.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Invalidate discards the cached token if it is still token, forcing the next
+// Token call to fetch a new one. Tokens that were already replaced by a
+// concurrent refresh are left alone.
+func (m *tokenManager) Invalidate(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if token != "" && token == m.token {
+		m.token = ""
+		m.refreshAt = time.Time{}
+	}
+}
+
+func (m *tokenManager) refresh(ctx context.Context, refresh *tokenRefresh) {
+	ctx, cancel := context.WithTimeout(ctx, tokenRequestTimeout)
+	defer cancel()
+
+	tflog.SubsystemDebug(ctx, clientLogSubsystem, "Requesting OAuth access token", map[string]interface{}{
+		"token_url": m.tokenURL,
+		"client_id": m.clientID,
+	})
+	token, expiry, err := fetchClientCredentialsToken(ctx, m.httpClient, m.tokenURL, m.clientID, m.clientSecret)
+	if err == nil {
+		tflog.SubsystemDebug(ctx, clientLogSubsystem, "Obtained OAuth access token", map[string]interface{}{
+			"expires_at": expiry.Format(time.RFC3339),
+		})
+	}
+
+	m.mu.Lock()
+	if err == nil {
+		m.token, m.refreshAt = token, tokenRefreshTime(time.Now(), expiry)
+	}
+	m.pending = nil
+	m.mu.Unlock()
+
+	refresh.token, refresh.err = token, err
+	close(refresh.done)
+}
+
+// tokenRefreshTime returns when a token obtained at now and expiring at expiry
+// should be replaced: tokenRefreshWindow before expiry, or a quarter of the
+// token's lifetime before it if that is shorter.
+func tokenRefreshTime(now, expiry time.Time) time.Time {
+	window := min(tokenRefreshWindow, expiry.Sub(now)/4)
+	return expiry.Add(-window)
+}
+
+// tokenResponse is the OAuth2 token endpoint response (RFC 6749 section 5.1).
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// fetchClientCredentialsToken requests an access token from tokenURL and
+// returns it together with its expiry time. The client authenticates with
+// HTTP Basic authentication, which RFC 6749 section 2.3.1 requires every
+// token endpoint to support. Tokens without expires_in are assumed to live
+// for defaultTokenLifetime.
+func fetchClientCredentialsToken(ctx context.Context, httpClient *http.Client, tokenURL, clientID, clientSecret string) (string, time.Time, error) {
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error building token request: %w", err)
+	}
+	// The credentials are form-encoded before being used as the user name
+	// and password (RFC 6749 section 2.3.1).
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	requested := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error requesting access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("error decoding token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("token endpoint response did not include an access token")
+	}
+
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	return token.AccessToken, requested.Add(lifetime), nil
+}
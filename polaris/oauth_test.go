@@ -0,0 +1,207 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+// newTestTokenServer serves client credentials tokens, answering every request
+// with the JSON body built by response from the number of tokens issued so
+// far. It returns the server and a counter of issued tokens.
+func newTestTokenServer(t *testing.T, response func(n int64) string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var issued atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, response(n))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &issued
+}
+
+func TestTokenManagerCachesTokenWithoutExpiresIn(t *testing.T) {
+	srv, issued := newTestTokenServer(t, func(n int64) string {
+		return fmt.Sprintf(`{"access_token": "token-%d", "token_type": "Bearer"}`, n)
+	})
+	m := newTokenManager(srv.URL, "id", "secret", nil)
+
+	for i := 0; i < 5; i++ {
+		token, err := m.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token: %s", err)
+		}
+		if token != "token-1" {
+			t.Fatalf("Token = %q, want the cached token-1", token)
+		}
+	}
+	if got := issued.Load(); got != 1 {
+		t.Errorf("token endpoint called %d times, want 1", got)
+	}
+}
+
+func TestTokenManagerCachesShortLivedToken(t *testing.T) {
+	srv, issued := newTestTokenServer(t, func(n int64) string {
+		return fmt.Sprintf(`{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 30}`, n)
+	})
+	m := newTokenManager(srv.URL, "id", "secret", nil)
+
+	for i := 0; i < 5; i++ {
+		if _, err := m.Token(context.Background()); err != nil {
+			t.Fatalf("Token: %s", err)
+		}
+	}
+	if got := issued.Load(); got != 1 {
+		t.Errorf("token endpoint called %d times, want 1", got)
+	}
+}
+
+func TestTokenManagerSharesConcurrentRefresh(t *testing.T) {
+	srv, issued := newTestTokenServer(t, func(n int64) string {
+		// Hold the response so every caller arrives while it is in flight.
+		time.Sleep(100 * time.Millisecond)
+		return fmt.Sprintf(`{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	})
+	m := newTokenManager(srv.URL, "id", "secret", nil)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	errs := make([]error, len(tokens))
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], errs[i] = m.Token(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if errs[i] != nil {
+			t.Fatalf("Token: %s", errs[i])
+		}
+		if tokens[i] != "token-1" {
+			t.Errorf("caller %d got %q, want the shared token-1", i, tokens[i])
+		}
+	}
+	if got := issued.Load(); got != 1 {
+		t.Errorf("token endpoint called %d times, want 1", got)
+	}
+}
+
+func TestTokenManagerInvalidate(t *testing.T) {
+	srv, issued := newTestTokenServer(t, func(n int64) string {
+		return fmt.Sprintf(`{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	})
+	m := newTokenManager(srv.URL, "id", "secret", nil)
+
+	if _, err := m.Token(context.Background()); err != nil {
+		t.Fatalf("Token: %s", err)
+	}
+	m.Invalidate("token-1")
+	token, err := m.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %s", err)
+	}
+	if token != "token-2" {
+		t.Errorf("Token after Invalidate = %q, want token-2", token)
+	}
+
+	// A stale token must not evict the one that replaced it.
+	m.Invalidate("token-1")
+	if token, _ := m.Token(context.Background()); token != "token-2" {
+		t.Errorf("Token after stale Invalidate = %q, want token-2", token)
+	}
+	if got := issued.Load(); got != 2 {
+		t.Errorf("token endpoint called %d times, want 2", got)
+	}
+}
+
+func TestFetchClientCredentialsTokenUsesBasicAuth(t *testing.T) {
+	var (
+		user, password string
+		ok             bool
+		form           url.Values
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok = r.BasicAuth()
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %s", err)
+		}
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	defer srv.Close()
+
+	if _, _, err := fetchClientCredentialsToken(context.Background(), srv.Client(), srv.URL, "my id", "s3cr:t&"); err != nil {
+		t.Fatalf("fetchClientCredentialsToken: %s", err)
+	}
+	if !ok || user != "my+id" || password != "s3cr%3At%26" {
+		t.Errorf("basic auth = %q, %q, %t, want the form-encoded client_id and client_secret", user, password, ok)
+	}
+	if got := form.Get("grant_type"); got != "client_credentials" {
+		t.Errorf("grant_type = %q, want client_credentials", got)
+	}
+	if form.Has("client_id") || form.Has("client_secret") {
+		t.Errorf("credentials sent in the form body: %v", form)
+	}
+}
+
+func TestClientRefreshesTokenAfterUnauthorized(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutTable("p", map[string]interface{}{"name": "events", "type": "detail"})
+
+	auth := NewClientCredentialsAuthenticator(srv.TokenURL(), "id", "secret", nil)
+	client := NewClient(srv.URL, auth, WithRetryPolicy(RetryPolicy{}))
+	if _, err := client.GetTable(context.Background(), "p", "events"); err != nil {
+		t.Fatalf("GetTable: %s", err)
+	}
+
+	// Polaris revokes the cached token before it expires.
+	srv.InjectFault(polaristest.Fault{StatusCode: http.StatusUnauthorized, Count: 1})
+	if _, err := client.GetTable(context.Background(), "p", "events"); err != nil {
+		t.Fatalf("GetTable after 401: %s", err)
+	}
+	if got := srv.TokensIssued(); got != 2 {
+		t.Errorf("%d tokens issued, want 2", got)
+	}
+
+	// A second 401 in a row is reported rather than refreshed again.
+	srv.InjectFault(polaristest.Fault{PathPrefix: "/v1/", StatusCode: http.StatusUnauthorized, Count: 2})
+	_, err := client.GetTable(context.Background(), "p", "events")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GetTable error = %v, want a 401 APIError", err)
+	}
+}
+
+func TestTokenRefreshTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		lifetime time.Duration
+		want     time.Duration
+	}{
+		{time.Hour, time.Hour - tokenRefreshWindow},
+		{4 * time.Minute, 3 * time.Minute},
+		{40 * time.Second, 30 * time.Second},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := tokenRefreshTime(now, now.Add(tt.lifetime)).Sub(now); got != tt.want {
+			t.Errorf("lifetime %s: refresh after %s, want %s", tt.lifetime, got, tt.want)
+		}
+	}
+}
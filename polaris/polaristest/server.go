@@ -0,0 +1,681 @@
+// Package polaristest provides an in-memory fake of the Polaris v1 API so the
+// provider can be exercised without a Polaris account.
+//
+// The fake implements the table, connection and job endpoints the provider
+// uses, keeps their state in memory, answers with the same status codes and
+// ErrorResponse bodies as Polaris, and supports injecting latency and
+// failures:
+//
+//	srv := polaristest.NewServer()
+//	defer srv.Close()
+//	srv.InjectFault(polaristest.Fault{Method: http.MethodPost, StatusCode: http.StatusTooManyRequests, Count: 1})
+//
+// Point the provider's base_url at srv.URL and use any api_key.
+package polaristest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake Polaris API backed by an httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	projects map[string]*project
+	faults   []*Fault
+	latency  time.Duration
+	pageSize int
+	requests int
+	tokens   int
+	header   http.Header
+}
+
+type project struct {
+	tables      map[string]map[string]interface{}
+	connections map[string]map[string]interface{}
+	jobs        map[string]map[string]interface{}
+}
+
+// Fault makes the server fail matching requests instead of serving them.
+type Fault struct {
+	// Method restricts the fault to one HTTP method; empty matches any.
+	Method string
+	// PathPrefix restricts the fault to paths starting with it; empty
+	// matches any.
+	PathPrefix string
+	// StatusCode is the status returned, e.g. 429 or 500.
+	StatusCode int
+	// RetryAfter, when set, is sent as the Retry-After header.
+	RetryAfter string
+	// Count is how many requests fail before the fault clears itself. Zero
+	// keeps failing until ClearFaults is called.
+	Count int
+}
+
+// NewServer starts a fake Polaris API with no projects. Projects are created
+// implicitly on first use.
+func NewServer() *Server {
+	s := &Server{
+		projects: make(map[string]*project),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// TokenURL is an OAuth2 client credentials token endpoint served by the fake.
+// It issues a new opaque access token for any client_id and client_secret.
+func (s *Server) TokenURL() string {
+	return s.URL + "/oauth/token"
+}
+
+// InjectFault adds a failure to the server. Faults are checked in the order
+// they were added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected failure.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetPageSize limits list responses to n items per page when the request does
+// not ask for a limit itself. Zero returns everything in one page.
+func (s *Server) SetPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = n
+}
+
+// RequestCount returns the number of API requests served so far, including
+// failed ones.
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// LastRequestHeader returns the headers of the most recent request served,
+// for example to check the credentials the client sent.
+func (s *Server) LastRequestHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header.Clone()
+}
+
+// TokensIssued returns the number of access tokens issued by TokenURL.
+func (s *Server) TokensIssued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens
+}
+
+// PutTable stores table in projectID as if it had been created through the
+// API, filling in the server-managed fields. It is meant for seeding state,
+// for example to test imports.
+func (s *Server) PutTable(projectID string, table map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.project(projectID).tables[table["name"].(string)] = newTable(table)
+}
+
+// Table returns a copy of the named table as Polaris would return it.
+func (s *Server) Table(projectID, name string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table, ok := s.project(projectID).tables[name]
+	return copyObject(table), ok
+}
+
+// PutConnection stores connection in projectID as if it had been created
+// through the API.
+func (s *Server) PutConnection(projectID string, connection map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.project(projectID).connections[connection["name"].(string)] = newConnection(connection)
+}
+
+// Connection returns a copy of the named connection, including its secrets.
+func (s *Server) Connection(projectID, name string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	connection, ok := s.project(projectID).connections[name]
+	return copyObject(connection), ok
+}
+
+func (s *Server) project(id string) *project {
+	p, ok := s.projects[id]
+	if !ok {
+		p = &project{
+			tables:      make(map[string]map[string]interface{}),
+			connections: make(map[string]map[string]interface{}),
+			jobs:        make(map[string]map[string]interface{}),
+		}
+		s.projects[id] = p
+	}
+	return p
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	s.header = r.Header.Clone()
+	latency := s.latency
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil {
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		writeError(w, fault.StatusCode, http.StatusText(fault.StatusCode), "injected fault", "")
+		return
+	}
+
+	if r.URL.Path == "/oauth/token" {
+		s.serveToken(w, r)
+		return
+	}
+
+	if r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "missing credentials", "")
+		return
+	}
+
+	// /v1/projects/{projectID}/{collection}[/{name}]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[0] != "v1" || parts[1] != "projects" {
+		writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("no route for %s", r.URL.Path), "")
+		return
+	}
+	for i, part := range parts {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			parts[i] = unescaped
+		}
+	}
+	projectID, collection := parts[2], parts[3]
+	name := ""
+	if len(parts) == 5 {
+		name = parts[4]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.project(projectID)
+	switch collection {
+	case "tables":
+		s.serveObjects(w, r, p.tables, name, "Table", newTable, validateTable, nil)
+	case "connections":
+		s.serveObjects(w, r, p.connections, name, "Connection", newConnection, validateConnection, hideSecrets)
+	case "jobs":
+		s.serveJobs(w, r, p.jobs, name)
+	default:
+		writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("unknown collection %q", collection), "")
+	}
+}
+
+// matchFault returns the first fault matching r, consuming one use of it.
+// The caller must hold s.mu.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.PathPrefix != "" && !strings.HasPrefix(r.URL.Path, f.PathPrefix) {
+			continue
+		}
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		matched := *f
+		return &matched
+	}
+	return nil
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "unsupported_grant_type"})
+		return
+	}
+	clientID, clientSecret := clientCredentials(r)
+	if clientID == "" || clientSecret == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	s.tokens++
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "fake-token-" + newID(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+// clientCredentials returns the client_id and client_secret of a token
+// request, taken from HTTP Basic authentication or, failing that, from the
+// form body (RFC 6749 section 2.3.1).
+func clientCredentials(r *http.Request) (string, string) {
+	if user, password, ok := r.BasicAuth(); ok {
+		clientID, err1 := url.QueryUnescape(user)
+		clientSecret, err2 := url.QueryUnescape(password)
+		if err1 != nil || err2 != nil {
+			return "", ""
+		}
+		return clientID, clientSecret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
+
+// serveObjects implements the create/list/get/update/delete endpoints of a
+// collection keyed by name. view, when set, transforms an object before it is
+// returned to the client. The caller must hold s.mu.
+func (s *Server) serveObjects(
+	w http.ResponseWriter,
+	r *http.Request,
+	objects map[string]map[string]interface{},
+	name, kind string,
+	create func(map[string]interface{}) map[string]interface{},
+	validate func(map[string]interface{}) *apiError,
+	view func(map[string]interface{}) map[string]interface{},
+) {
+	if view == nil {
+		view = copyObject
+	}
+
+	if name == "" {
+		switch r.Method {
+		case http.MethodGet:
+			names := make([]string, 0, len(objects))
+			for n := range objects {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			values := make([]interface{}, len(names))
+			for i, n := range names {
+				values[i] = view(objects[n])
+			}
+			s.writePage(w, r, values)
+		case http.MethodPost:
+			body, ok := decodeBody(w, r)
+			if !ok {
+				return
+			}
+			if err := validate(body); err != nil {
+				err.write(w)
+				return
+			}
+			objectName := body["name"].(string)
+			if _, exists := objects[objectName]; exists {
+				writeError(w, http.StatusConflict, "AlreadyExists", fmt.Sprintf("%s %s already exists", kind, objectName), "name")
+				return
+			}
+			object := create(body)
+			objects[objectName] = object
+			writeJSON(w, http.StatusCreated, view(object))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	key, object := lookup(objects, name)
+	if object == nil {
+		writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %s not found", kind, name), "")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, view(object))
+	case http.MethodPut:
+		body, ok := decodeBody(w, r)
+		if !ok {
+			return
+		}
+		if err := validate(body); err != nil {
+			err.write(w)
+			return
+		}
+		if body["name"] != object["name"] {
+			writeError(w, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("%s name cannot be changed", kind), "name")
+			return
+		}
+		updated := create(body)
+		for _, field := range []string{"id", "createdOnTimestamp", "createdByUser"} {
+			if v, ok := object[field]; ok {
+				updated[field] = v
+			}
+		}
+		if version, ok := object["version"].(float64); ok {
+			updated["version"] = version + 1
+		}
+		keepStoragePolicy(updated, object)
+		objects[key] = updated
+		writeJSON(w, http.StatusOK, view(updated))
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveJobs(w http.ResponseWriter, r *http.Request, jobs map[string]map[string]interface{}, id string) {
+	if id == "" {
+		switch r.Method {
+		case http.MethodGet:
+			ids := make([]string, 0, len(jobs))
+			for jobID := range jobs {
+				ids = append(ids, jobID)
+			}
+			sort.Strings(ids)
+			values := make([]interface{}, len(ids))
+			for i, jobID := range ids {
+				values[i] = copyObject(jobs[jobID])
+			}
+			s.writePage(w, r, values)
+		case http.MethodPost:
+			body, ok := decodeBody(w, r)
+			if !ok {
+				return
+			}
+			if _, ok := body["type"].(string); !ok {
+				writeError(w, http.StatusBadRequest, "InvalidArgument", "job type is required", "type")
+				return
+			}
+			job := copyObject(body)
+			job["id"] = newID()
+			job["executionStatus"] = "pending"
+			job["createdTimestamp"] = now()
+			jobs[job["id"].(string)] = job
+			writeJSON(w, http.StatusCreated, job)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	job, ok := jobs[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("Job %s not found", id), "")
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, copyObject(job))
+}
+
+// writePage writes one page of values. Pages are selected with the limit and
+// offset query parameters; when more values remain, a Link header with
+// rel="next" points at the following page.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, values []interface{}) {
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = s.pageSize
+	}
+	if offset < 0 || offset > len(values) {
+		offset = len(values)
+	}
+
+	end := len(values)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+		next := *r.URL
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(end))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"values": values[offset:end]})
+}
+
+// lookup finds an object by name, falling back to its server-assigned ID.
+func lookup(objects map[string]map[string]interface{}, nameOrID string) (string, map[string]interface{}) {
+	if object, ok := objects[nameOrID]; ok {
+		return nameOrID, object
+	}
+	for key, object := range objects {
+		if object["id"] == nameOrID {
+			return key, object
+		}
+	}
+	return "", nil
+}
+
+func newTable(body map[string]interface{}) map[string]interface{} {
+	table := copyObject(body)
+	table["id"] = newID()
+	defaults := map[string]interface{}{
+		"version":                 float64(1),
+		"availability":            "available",
+		"partitioningGranularity": "day",
+		"timeResolution":          "millisecond",
+		"schemaMode":              "flexible",
+		"segmentCompactedBytes":   float64(0),
+		"segmentTotalBytes":       float64(0),
+		"totalDataSizeBytes":      float64(0),
+		"totalRows":               float64(0),
+	}
+	for field, value := range defaults {
+		if v, ok := table[field]; !ok || v == "" || v == float64(0) {
+			table[field] = value
+		}
+	}
+	if _, ok := table["schema"]; !ok {
+		table["schema"] = []interface{}{}
+	}
+	table["queryableSchema"] = table["schema"]
+	table["createdOnTimestamp"] = now()
+	table["modifiedOnTimestamp"] = table["createdOnTimestamp"]
+	table["createdByUser"] = map[string]interface{}{"username": "polaristest", "userId": "polaristest"}
+	table["modifiedByUser"] = table["createdByUser"]
+	return table
+}
+
+func validateTable(body map[string]interface{}) *apiError {
+	if err := validateName(body); err != nil {
+		return err
+	}
+	switch body["type"] {
+	case "detail", "aggregate":
+	default:
+		return &apiError{status: http.StatusBadRequest, code: "InvalidArgument", message: "table type must be detail or aggregate", target: "type"}
+	}
+
+	columns, _ := body["schema"].([]interface{})
+	var details []errorDetail
+	for i, raw := range columns {
+		column, _ := raw.(map[string]interface{})
+		if name, _ := column["name"].(string); name == "" {
+			details = append(details, errorDetail{Code: "InvalidValue", Message: "column name is required", Target: fmt.Sprintf("schema[%d].name", i)})
+		}
+		if dataType, _ := column["dataType"].(string); dataType == "" {
+			details = append(details, errorDetail{Code: "InvalidValue", Message: "column data type is required", Target: fmt.Sprintf("schema[%d].dataType", i)})
+		}
+	}
+	if len(details) > 0 {
+		return &apiError{status: http.StatusBadRequest, code: "InvalidArgument", message: "invalid table schema", details: details}
+	}
+	return nil
+}
+
+// keepStoragePolicy carries the storage policy rules of a table over to its
+// update when the update leaves them out, as Polaris does.
+func keepStoragePolicy(updated, table map[string]interface{}) {
+	current, ok := table["storagePolicy"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	policy, _ := updated["storagePolicy"].(map[string]interface{})
+	if policy == nil {
+		policy = make(map[string]interface{})
+	}
+	for rule, value := range current {
+		if _, ok := policy[rule]; !ok {
+			policy[rule] = value
+		}
+	}
+	updated["storagePolicy"] = policy
+}
+
+func newConnection(body map[string]interface{}) map[string]interface{} {
+	connection := copyObject(body)
+	connection["createdOnTimestamp"] = now()
+	connection["modifiedOnTimestamp"] = connection["createdOnTimestamp"]
+	return connection
+}
+
+func validateConnection(body map[string]interface{}) *apiError {
+	if err := validateName(body); err != nil {
+		return err
+	}
+	if connectionType, _ := body["type"].(string); connectionType == "" {
+		return &apiError{status: http.StatusBadRequest, code: "InvalidArgument", message: "connection type is required", target: "type"}
+	}
+	return nil
+}
+
+func validateName(body map[string]interface{}) *apiError {
+	if name, _ := body["name"].(string); name == "" {
+		return &apiError{status: http.StatusBadRequest, code: "InvalidArgument", message: "name is required", target: "name"}
+	}
+	return nil
+}
+
+// hideSecrets mirrors Polaris never returning secret values: only the secret
+// type and identifiers such as the username are echoed back.
+func hideSecrets(connection map[string]interface{}) map[string]interface{} {
+	view := copyObject(connection)
+	secrets, ok := view["secrets"].(map[string]interface{})
+	if !ok {
+		return view
+	}
+	visible := make(map[string]interface{})
+	for _, field := range []string{"type", "username", "tenantId", "clientId", "sasKeyName", "apiKey"} {
+		if v, ok := secrets[field]; ok {
+			visible[field] = v
+		}
+	}
+	view["secrets"] = visible
+	return view
+}
+
+// apiError is a Polaris ErrorResponse body.
+type apiError struct {
+	status  int
+	code    string
+	message string
+	target  string
+	details []errorDetail
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Target  string `json:"target,omitempty"`
+}
+
+func (e *apiError) write(w http.ResponseWriter) {
+	body := map[string]interface{}{
+		"code":    e.code,
+		"message": e.message,
+	}
+	if e.target != "" {
+		body["target"] = e.target
+	}
+	if len(e.details) > 0 {
+		body["details"] = e.details
+	}
+	writeJSON(w, e.status, body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message, target string) {
+	(&apiError{status: status, code: code, message: message, target: target}).write(w)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", newID())
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("malformed request body: %s", err), "")
+		return nil, false
+	}
+	return body, true
+}
+
+// copyObject deep-copies a decoded JSON object so callers cannot mutate the
+// server's state.
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(object)
+	var copied map[string]interface{}
+	_ = json.Unmarshal(raw, &copied)
+	return copied
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	h := hex.EncodeToString(b)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
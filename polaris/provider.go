@@ -1,11 +1,27 @@
 package polaris
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// defaultReadTimeout is how long reading a resource or data source may
+	// take by default, including retries and pagination.
+	defaultReadTimeout = 5 * time.Minute
+
+	// defaultRequestTimeout is the default request_timeout, the limit on a
+	// single HTTP call to Polaris.
+	defaultRequestTimeout = time.Minute
 )
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"base_url": {
 				Type:        schema.TypeString,
@@ -14,27 +30,174 @@ func Provider() *schema.Provider {
 			},
 			"api_key": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("POLARIS_API_KEY", nil),
 			},
+			"bearer_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("POLARIS_BEARER_TOKEN", nil),
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("POLARIS_CLIENT_ID", nil),
+			},
+			"client_secret": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("POLARIS_CLIENT_SECRET", nil),
+			},
+			"token_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("POLARIS_TOKEN_URL", nil),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      DefaultRetryPolicy().MaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"min_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DefaultRetryPolicy().MinBackoff.String(),
+				ValidateFunc: validateDuration,
+			},
+			"max_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DefaultRetryPolicy().MaxBackoff.String(),
+				ValidateFunc: validateDuration,
+			},
+			"request_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultRequestTimeout.String(),
+				ValidateFunc: validateDuration,
+			},
+			// The rate limits are opt-in: zero leaves requests unthrottled.
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_concurrent_mutations": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"polaris_table":      resourcePolarisTable(),
 			"polaris_connection": resourcePolarisConnection(),
 		},
-		ConfigureFunc: func(d *schema.ResourceData) (interface{}, error) {
-			return NewClient(
-				d.Get("base_url").(string),
-				d.Get("api_key").(string),
-			), nil
+		DataSourcesMap: map[string]*schema.Resource{
+			"polaris_table":       dataSourcePolarisTable(),
+			"polaris_tables":      dataSourcePolarisTables(),
+			"polaris_connection":  dataSourcePolarisConnection(),
+			"polaris_connections": dataSourcePolarisConnections(),
 		},
 	}
+	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return providerConfigure(ctx, d, sensitiveSchemaKeys(p))
+	}
+	return p
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+// providerConfigure builds the Client shared by every resource and data
+// source. sensitiveLogKeys lists the attributes masked in the client's logs.
+func providerConfigure(ctx context.Context, d *schema.ResourceData, sensitiveLogKeys []string) (interface{}, diag.Diagnostics) {
 	baseURL := d.Get("base_url").(string)
-	apiKey := d.Get("api_key").(string)
 
-	client := NewClient(baseURL, apiKey)
+	auth, diags := providerAuthenticator(d)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	retry := RetryPolicy{
+		MaxRetries: d.Get("max_retries").(int),
+	}
+	retry.MinBackoff, _ = time.ParseDuration(d.Get("min_backoff").(string))
+	retry.MaxBackoff, _ = time.ParseDuration(d.Get("max_backoff").(string))
+	if retry.MinBackoff > retry.MaxBackoff {
+		return nil, diag.Errorf("min_backoff (%s) must not be greater than max_backoff (%s)", retry.MinBackoff, retry.MaxBackoff)
+	}
+	requestTimeout, _ := time.ParseDuration(d.Get("request_timeout").(string))
+
+	client := NewClient(baseURL, auth,
+		WithRetryPolicy(retry),
+		WithSensitiveLogKeys(sensitiveLogKeys...),
+		WithRequestTimeout(requestTimeout),
+		WithRateLimit(
+			d.Get("requests_per_second").(float64),
+			d.Get("burst").(int),
+			d.Get("max_concurrent_mutations").(int),
+		),
+	)
 	return client, nil
 }
+
+// providerAuthenticator selects the Authenticator from whichever credentials
+// are configured: an API key, a bearer token, or OAuth client credentials.
+// Exactly one of them must be present.
+func providerAuthenticator(d *schema.ResourceData) (Authenticator, diag.Diagnostics) {
+	apiKey := d.Get("api_key").(string)
+	bearerToken := d.Get("bearer_token").(string)
+	clientID := d.Get("client_id").(string)
+	clientSecret := d.Get("client_secret").(string)
+	tokenURL := d.Get("token_url").(string)
+	useClientCredentials := clientID != "" || clientSecret != "" || tokenURL != ""
+
+	configured := 0
+	for _, set := range []bool{apiKey != "", bearerToken != "", useClientCredentials} {
+		if set {
+			configured++
+		}
+	}
+	switch {
+	case configured == 0:
+		return nil, diag.Errorf("No Polaris credentials configured: set one of api_key (POLARIS_API_KEY), bearer_token (POLARIS_BEARER_TOKEN), or client_id (POLARIS_CLIENT_ID), client_secret (POLARIS_CLIENT_SECRET) and token_url (POLARIS_TOKEN_URL)")
+	case configured > 1:
+		return nil, diag.Errorf("Conflicting Polaris credentials configured: set only one of api_key, bearer_token, or client_id/client_secret/token_url")
+	}
+
+	switch {
+	case useClientCredentials:
+		if clientID == "" || clientSecret == "" || tokenURL == "" {
+			return nil, diag.Errorf("OAuth client credentials require client_id, client_secret and token_url to all be set")
+		}
+		return NewClientCredentialsAuthenticator(tokenURL, clientID, clientSecret, nil), nil
+	case bearerToken != "":
+		return NewBearerTokenAuthenticator(bearerToken), nil
+	default:
+		return NewAPIKeyAuthenticator(apiKey), nil
+	}
+}
+
+// validateDuration checks that a string attribute holds a non-negative Go
+// duration such as "500ms" or "30s".
+func validateDuration(v interface{}, k string) (ws []string, errs []error) {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration such as \"30s\": %s", k, err))
+		return
+	}
+	if d < 0 {
+		errs = append(errs, fmt.Errorf("%q must not be negative, got %s", k, d))
+	}
+	return
+}
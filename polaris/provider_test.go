@@ -0,0 +1,114 @@
+package polaris
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+// testProviderFactories serves the provider to Terraform in resource tests.
+var testProviderFactories = map[string]func() (*schema.Provider, error){
+	"polaris": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("InternalValidate: %s", err)
+	}
+}
+
+// testProviderConfig points the provider at srv. Retries back off for
+// milliseconds so injected faults do not slow tests down.
+func testProviderConfig(srv *polaristest.Server) string {
+	return fmt.Sprintf(`
+provider "polaris" {
+  base_url    = %q
+  api_key     = "test"
+  min_backoff = "1ms"
+  max_backoff = "10ms"
+}
+`, srv.URL)
+}
+
+// testAccPreCheck skips tests that drive the provider through Terraform when
+// no Terraform CLI is available. Without one the SDK would try to download
+// it and, failing that, abort the whole test binary.
+func testAccPreCheck(t *testing.T) {
+	t.Helper()
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") != "" {
+		return
+	}
+	if _, err := exec.LookPath("terraform"); err != nil {
+		t.Skip("no Terraform CLI found; install terraform or set TF_ACC_TERRAFORM_PATH")
+	}
+}
+
+func TestProviderRequestTimeout(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+		want   time.Duration
+	}{
+		{map[string]interface{}{}, defaultRequestTimeout},
+		{map[string]interface{}{"request_timeout": "20s"}, 20 * time.Second},
+		{map[string]interface{}{"request_timeout": "0s"}, 0},
+	}
+	for _, tt := range tests {
+		tt.config["base_url"] = "https://api.example.com"
+		tt.config["api_key"] = "key"
+		d := schema.TestResourceDataRaw(t, Provider().Schema, tt.config)
+
+		meta, diags := providerConfigure(context.Background(), d, nil)
+		if diags.HasError() {
+			t.Fatalf("providerConfigure(%v): %v", tt.config, diags)
+		}
+		if got := meta.(*Client).requestTimeout; got != tt.want {
+			t.Errorf("request_timeout %v: client timeout = %s, want %s", tt.config["request_timeout"], got, tt.want)
+		}
+	}
+}
+
+func TestProviderTimeouts(t *testing.T) {
+	type timeouts struct {
+		create, read, update, delete time.Duration
+	}
+	tests := map[string]struct {
+		resource *schema.Resource
+		want     timeouts
+	}{
+		"resource polaris_table":          {resourcePolarisTable(), timeouts{20 * time.Minute, 5 * time.Minute, 20 * time.Minute, 20 * time.Minute}},
+		"resource polaris_connection":     {resourcePolarisConnection(), timeouts{10 * time.Minute, 5 * time.Minute, 10 * time.Minute, 10 * time.Minute}},
+		"data source polaris_table":       {dataSourcePolarisTable(), timeouts{read: 5 * time.Minute}},
+		"data source polaris_tables":      {dataSourcePolarisTables(), timeouts{read: 5 * time.Minute}},
+		"data source polaris_connection":  {dataSourcePolarisConnection(), timeouts{read: 5 * time.Minute}},
+		"data source polaris_connections": {dataSourcePolarisConnections(), timeouts{read: 5 * time.Minute}},
+	}
+	value := func(d *time.Duration) time.Duration {
+		if d == nil {
+			return 0
+		}
+		return *d
+	}
+	for name, tt := range tests {
+		if tt.resource.Timeouts == nil {
+			t.Errorf("%s has no timeouts", name)
+			continue
+		}
+		got := timeouts{
+			create: value(tt.resource.Timeouts.Create),
+			read:   value(tt.resource.Timeouts.Read),
+			update: value(tt.resource.Timeouts.Update),
+			delete: value(tt.resource.Timeouts.Delete),
+		}
+		if got != tt.want {
+			t.Errorf("%s timeouts = %+v, want %+v", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package polaris
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// requestLimiter throttles the requests a Client sends. Every resource
+// configured from the same provider block shares one Client, and therefore
+// one limiter, so the budget applies to the whole apply rather than to each
+// resource.
+type requestLimiter struct {
+	// bucket is the token bucket every attempt waits on. A nil bucket
+	// admits requests immediately.
+	bucket *rate.Limiter
+
+	// maxMutations caps concurrent mutating requests per project. Zero means
+	// no cap.
+	maxMutations int
+
+	mu       sync.Mutex
+	projects map[string]chan struct{}
+}
+
+func newRequestLimiter(requestsPerSecond float64, burst, maxMutations int) *requestLimiter {
+	l := &requestLimiter{
+		maxMutations: maxMutations,
+		projects:     make(map[string]chan struct{}),
+	}
+	if requestsPerSecond > 0 {
+		// Without an explicit burst, allow one second's worth of requests.
+		if burst < 1 {
+			burst = int(math.Ceil(requestsPerSecond))
+		}
+		l.bucket = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+	return l
+}
+
+// wait blocks until the token bucket admits another request or ctx is done.
+func (l *requestLimiter) wait(ctx context.Context) error {
+	if l.bucket == nil {
+		return nil
+	}
+	return l.bucket.Wait(ctx)
+}
+
+// acquire reserves one of the project's mutation slots and returns the
+// function that releases it. It blocks while the project is at its cap.
+func (l *requestLimiter) acquire(ctx context.Context, projectID string) (func(), error) {
+	if l.maxMutations <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	slots, ok := l.projects[projectID]
+	if !ok {
+		slots = make(chan struct{}, l.maxMutations)
+		l.projects[projectID] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// isMutation reports whether method changes state on the server.
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// projectFromPath extracts the project ID from an API path of the form
+// /v1/projects/{projectID}/...; it returns "" for paths outside a project.
+func projectFromPath(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) < 3 || parts[1] != "projects" {
+		return ""
+	}
+	return parts[2]
+}
@@ -0,0 +1,114 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestLimiterWait(t *testing.T) {
+	// Unlimited by default.
+	l := newRequestLimiter(0, 0, 0)
+	for i := 0; i < 100; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait: %s", err)
+		}
+	}
+
+	// The burst passes at once, the next request waits for a token.
+	l = newRequestLimiter(20, 2, 0)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait: %s", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("3 requests at 20/s with burst 2 took %s, want at least 25ms", elapsed)
+	}
+
+	// Waiting ends when the context is cancelled.
+	l = newRequestLimiter(0.001, 1, 0)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("wait succeeded with an exhausted bucket and a cancelled context")
+	}
+}
+
+func TestRequestLimiterDefaultBurst(t *testing.T) {
+	l := newRequestLimiter(2.5, 0, 0)
+	if got := l.bucket.Burst(); got != 3 {
+		t.Errorf("burst = %d, want 3", got)
+	}
+}
+
+func TestRequestLimiterAcquire(t *testing.T) {
+	l := newRequestLimiter(0, 0, 2)
+	ctx := context.Background()
+
+	release1, err := l.acquire(ctx, "p")
+	if err != nil {
+		t.Fatalf("acquire: %s", err)
+	}
+	if _, err := l.acquire(ctx, "p"); err != nil {
+		t.Fatalf("acquire: %s", err)
+	}
+
+	// Other projects have their own slots.
+	if _, err := l.acquire(ctx, "other"); err != nil {
+		t.Fatalf("acquire for another project: %s", err)
+	}
+
+	// A third mutation in the project blocks until cancelled...
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(cancelled, "p"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire at the cap = %v, want context.DeadlineExceeded", err)
+	}
+
+	// ...or until a slot is released.
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := l.acquire(ctx, "p")
+		acquired <- err
+	}()
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquire at the cap returned %v before a slot was released", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	release1()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("acquire after release: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire still blocked after a slot was released")
+	}
+}
+
+func TestProjectFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/v1/projects/p/tables", "p"},
+		{"/v1/projects/p/tables/events", "p"},
+		{"v1/projects/p/connections", "p"},
+		{"/v1/projects", ""},
+		{"/v1/projects/", ""},
+		{"/v1/jobs/j", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := projectFromPath(tt.path); got != tt.want {
+			t.Errorf("projectFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -1,342 +1,315 @@
 package polaris
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"io/ioutil"
-	"net/http"
 )
 
+// connectionDefaultTimeout is how long connection operations may take by
+// default, including retries.
+const connectionDefaultTimeout = 10 * time.Minute
+
 func resourcePolarisConnection() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePolarisConnectionCreate,
-		Read:   resourcePolarisConnectionRead,
-		Update: resourcePolarisConnectionUpdate,
-		Delete: resourcePolarisConnectionDelete,
-
-		Schema: map[string]*schema.Schema{
-			"project_id": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"type": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ValidateFunc: validation.StringInSlice([]string{"confluent", "kafka", "kinesis", "s3"}, false),
-			},
-			"description": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"bootstrap_servers": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"client_rack": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"ssl": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"truststore": {
-							Type:     schema.TypeList,
-							Optional: true,
-							MaxItems: 1,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"type": {
-										Type:     schema.TypeString,
-										Required: true,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			"topic_name": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"topic_name_is_pattern": {
-				Type:     schema.TypeBool,
-				Optional: true,
-			},
-			"secrets": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"type": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"username": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"password": {
-							Type:      schema.TypeString,
-							Optional:  true,
-							Sensitive: true,
-						},
-					},
-				},
-			},
-			"aws_assumed_role_arn": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"aws_endpoint": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"stream": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"bucket": {
-				Type:     schema.TypeString,
-				Optional: true,
+		CreateContext: resourcePolarisConnectionCreate,
+		ReadContext:   resourcePolarisConnectionRead,
+		UpdateContext: resourcePolarisConnectionUpdate,
+		DeleteContext: resourcePolarisConnectionDelete,
+		CustomizeDiff: resourcePolarisConnectionCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePolarisConnectionImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(connectionDefaultTimeout),
+			Read:   schema.DefaultTimeout(defaultReadTimeout),
+			Update: schema.DefaultTimeout(connectionDefaultTimeout),
+			Delete: schema.DefaultTimeout(connectionDefaultTimeout),
+		},
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourcePolarisConnectionV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePolarisConnectionStateUpgradeV0,
 			},
-			"prefix": {
-				Type:     schema.TypeString,
-				Optional: true,
+			{
+				Version: 1,
+				Type:    resourcePolarisConnectionV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePolarisConnectionStateUpgradeV1,
 			},
 		},
+
+		Schema: resourcePolarisConnectionSchema(),
 	}
 }
 
-func resourcePolarisConnectionCreate(d *schema.ResourceData, m interface{}) error {
-	client := m.(*Client)
-	projectID := d.Get("project_id").(string)
+// resourcePolarisConnectionSchema builds the connection schema: the common
+// attributes plus one block per connection type, of which exactly one must be
+// set. type is computed from the block.
+func resourcePolarisConnectionSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"project_id": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"type": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+	for _, connectionType := range connectionTypes {
+		s[connectionType] = &schema.Schema{
+			Type:         schema.TypeList,
+			Optional:     true,
+			MaxItems:     1,
+			ExactlyOneOf: connectionTypes,
+			Elem: &schema.Resource{
+				Schema: connectionHandlers[connectionType].schema(),
+			},
+		}
+	}
+	return s
+}
+
+// resourcePolarisConnectionCustomizeDiff sets type from the block that is
+// configured and runs the checks of that type. Polaris cannot change the type
+// of a connection, so moving to another block replaces it.
+func resourcePolarisConnectionCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	connectionType, _ := configuredConnectionType(d)
+	if connectionType == "" {
+		return nil
+	}
+
+	if validate := connectionHandlers[connectionType].validate; validate != nil {
+		if err := errors.Join(validate(d, connectionType+".0")...); err != nil {
+			return err
+		}
+	}
+
+	old, _ := d.GetChange("type")
+	if old.(string) == connectionType {
+		return nil
+	}
+	if err := d.SetNew("type", connectionType); err != nil {
+		return err
+	}
+	if old.(string) != "" {
+		return d.ForceNew("type")
+	}
+	return nil
+}
+
+// configuredConnectionType returns the type whose block is set, along with the
+// block's attributes.
+func configuredConnectionType(d interface{ Get(string) interface{} }) (string, map[string]interface{}) {
+	for _, connectionType := range connectionTypes {
+		blocks := d.Get(connectionType).([]interface{})
+		if len(blocks) == 0 {
+			continue
+		}
+		block, _ := blocks[0].(map[string]interface{})
+		return connectionType, block
+	}
+	return "", nil
+}
+
+// expandConnection builds the request body for the connection configured in d.
+func expandConnection(d *schema.ResourceData) (map[string]interface{}, error) {
+	connectionType, block := configuredConnectionType(d)
+	if connectionType == "" {
+		return nil, fmt.Errorf("exactly one of %q must be configured", connectionTypes)
+	}
 
-	connectionType := d.Get("type").(string)
 	connection := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"type":        connectionType,
 		"description": d.Get("description").(string),
 	}
+	if block != nil {
+		connectionHandlers[connectionType].expand(block, connection)
+	}
+	return connection, nil
+}
+
+// connectionErrorDiagnostics is apiErrorDiagnostics for connections. Polaris
+// reports targets such as "bootstrapServers" relative to the connection, so
+// targets naming a field of the connection type's block are moved into it.
+func connectionErrorDiagnostics(summary string, err error, connectionType string) diag.Diagnostics {
+	resourceSchema := resourcePolarisConnection().Schema
+	block, ok := resourceSchema[connectionType]
+	var apiErr *APIError
+	if !ok || !errors.As(err, &apiErr) {
+		return apiErrorDiagnostics(summary, err, resourceSchema)
+	}
 
-	switch connectionType {
-	case "confluent":
-		connection["bootstrapServers"] = d.Get("bootstrap_servers").(string)
-		connection["topicName"] = d.Get("topic_name").(string)
-		connection["topicNameIsPattern"] = d.Get("topic_name_is_pattern").(bool)
-		if v, ok := d.GetOk("secrets"); ok {
-			connection["secrets"] = expandSecrets(v.([]interface{}))
-		}
-	case "kafka":
-		connection["bootstrapServers"] = d.Get("bootstrap_servers").(string)
-		connection["clientRack"] = d.Get("client_rack").(string)
-		if v, ok := d.GetOk("ssl"); ok {
-			connection["ssl"] = expandSSL(v.([]interface{}))
-		}
-		connection["topicName"] = d.Get("topic_name").(string)
-		connection["topicNameIsPattern"] = d.Get("topic_name_is_pattern").(bool)
-		if v, ok := d.GetOk("secrets"); ok {
-			connection["secrets"] = expandSecrets(v.([]interface{}))
-		}
-	case "kinesis":
-		connection["awsAssumedRoleArn"] = d.Get("aws_assumed_role_arn").(string)
-		connection["awsEndpoint"] = d.Get("aws_endpoint").(string)
-		connection["stream"] = d.Get("stream").(string)
-	case "s3":
-		connection["awsAssumedRoleArn"] = d.Get("aws_assumed_role_arn").(string)
-		connection["awsEndpoint"] = d.Get("aws_endpoint").(string)
-		connection["bucket"] = d.Get("bucket").(string)
-		connection["prefix"] = d.Get("prefix").(string)
-		if v, ok := d.GetOk("secrets"); ok {
-			connection["secrets"] = expandSecrets(v.([]interface{}))
+	blockSchema := block.Elem.(*schema.Resource).Schema
+	inBlock := func(target string) string {
+		target = strings.TrimPrefix(target, "$.")
+		first, _, _ := strings.Cut(target, ".")
+		name, _ := parseTargetSegment(first)
+		if _, ok := blockSchema[toSnakeCase(name)]; ok && name != "" {
+			return connectionType + "." + target
 		}
+		return target
 	}
 
-	err := client.CreateConnection(projectID, connection)
-	if err != nil {
-		return fmt.Errorf("Error creating connection: %s", err)
+	scoped := *apiErr
+	scoped.Target = inBlock(apiErr.Target)
+	scoped.Details = make([]ErrorResponseDetail, len(apiErr.Details))
+	for i, detail := range apiErr.Details {
+		detail.Target = inBlock(detail.Target)
+		scoped.Details[i] = detail
 	}
-
-	d.SetId(connection["name"].(string))
-	return resourcePolarisConnectionRead(d, m)
+	return apiErrorDiagnostics(summary, &scoped, resourceSchema)
 }
 
-func resourcePolarisConnectionRead(d *schema.ResourceData, m interface{}) error {
+func resourcePolarisConnectionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*Client)
 	projectID := d.Get("project_id").(string)
-	name := d.Id()
 
-	resp, err := client.Get(fmt.Sprintf("/v1/projects/%s/connections/%s", projectID, name))
+	connection, err := expandConnection(d)
 	if err != nil {
-		return err
+		return diag.Errorf("Error creating connection: %s", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code: %d, error: %s", resp.StatusCode, string(bodyBytes))
+	_, err = client.CreateConnection(ctx, projectID, connection)
+	if IsConflict(err) {
+		return diag.Errorf("Connection %s already exists in project %s: %s", connection["name"], projectID, err)
 	}
+	if err != nil {
+		return connectionErrorDiagnostics("Error creating connection", err, connection["type"].(string))
+	}
+
+	d.SetId(compositeID(projectID, connection["name"].(string)))
+	return resourcePolarisConnectionRead(ctx, d, m)
+}
 
-	var connection map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&connection); err != nil {
-		return fmt.Errorf("Error decoding response: %s", err)
+func resourcePolarisConnectionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID, name, err := parseCompositeID(d.Id(), "project_id/connection_name")
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	if err := d.Set("name", connection["name"]); err != nil {
-		return err
+	connection, err := client.GetConnection(ctx, projectID, name)
+	if IsNotFound(err) {
+		tflog.Warn(ctx, "Connection not found, removing from state", map[string]interface{}{
+			"project_id": projectID,
+			"name":       name,
+		})
+		d.SetId("")
+		return nil
 	}
-	if err := d.Set("type", connection["type"]); err != nil {
-		return err
+	if IsUnauthorized(err) {
+		return diag.Errorf("Unauthorized: Please check your API key and permissions: %s", err)
 	}
-	if err := d.Set("description", connection["description"]); err != nil {
-		return err
+	if err != nil {
+		return diag.Errorf("Error reading connection: %s", err)
 	}
 
-	switch connection["type"] {
-	case "confluent":
-		if err := d.Set("bootstrap_servers", connection["bootstrapServers"]); err != nil {
-			return err
-		}
-		if err := d.Set("topic_name", connection["topicName"]); err != nil {
-			return err
-		}
-		if err := d.Set("topic_name_is_pattern", connection["topicNameIsPattern"]); err != nil {
-			return err
-		}
-		if secrets, ok := connection["secrets"].(map[string]interface{}); ok {
-			if err := d.Set("secrets", []map[string]interface{}{secrets}); err != nil {
-				return err
-			}
-		}
-	case "kafka":
-		if err := d.Set("bootstrap_servers", connection["bootstrapServers"]); err != nil {
-			return err
-		}
-		if err := d.Set("client_rack", connection["clientRack"]); err != nil {
-			return err
-		}
-		if ssl, ok := connection["ssl"].(map[string]interface{}); ok {
-			if err := d.Set("ssl", []map[string]interface{}{ssl}); err != nil {
-				return err
-			}
-		}
-		if err := d.Set("topic_name", connection["topicName"]); err != nil {
-			return err
-		}
-		if err := d.Set("topic_name_is_pattern", connection["topicNameIsPattern"]); err != nil {
-			return err
-		}
-		if secrets, ok := connection["secrets"].(map[string]interface{}); ok {
-			if err := d.Set("secrets", []map[string]interface{}{secrets}); err != nil {
-				return err
-			}
-		}
-	case "kinesis":
-		if err := d.Set("aws_assumed_role_arn", connection["awsAssumedRoleArn"]); err != nil {
-			return err
-		}
-		if err := d.Set("aws_endpoint", connection["awsEndpoint"]); err != nil {
-			return err
-		}
-		if err := d.Set("stream", connection["stream"]); err != nil {
-			return err
-		}
-	case "s3":
-		if err := d.Set("aws_assumed_role_arn", connection["awsAssumedRoleArn"]); err != nil {
-			return err
-		}
-		if err := d.Set("aws_endpoint", connection["awsEndpoint"]); err != nil {
-			return err
-		}
-		if err := d.Set("bucket", connection["bucket"]); err != nil {
-			return err
-		}
-		if err := d.Set("prefix", connection["prefix"]); err != nil {
-			return err
-		}
-		if secrets, ok := connection["secrets"].(map[string]interface{}); ok {
-			if err := d.Set("secrets", []map[string]interface{}{secrets}); err != nil {
-				return err
-			}
-		}
+	if err := d.Set("project_id", projectID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := setConnectionState(d, connection); err != nil {
+		return diag.Errorf("Error reading connection: %s", err)
 	}
 
 	return nil
 }
 
-func resourcePolarisConnectionUpdate(d *schema.ResourceData, m interface{}) error {
-	client := m.(*Client)
-	projectID := d.Get("project_id").(string)
-	connectionName := d.Get("name").(string)
-
-	connectionType := d.Get("type").(string)
-	connection := map[string]interface{}{
-		"name":        connectionName,
-		"type":        connectionType,
-		"description": d.Get("description").(string),
+// setConnectionState copies connection into d: the common attributes, and the
+// block of the connection's type. The blocks of other types are cleared.
+// Secret values are never returned by Polaris; those already in d are kept.
+func setConnectionState(d *schema.ResourceData, connection map[string]interface{}) error {
+	values := map[string]interface{}{
+		"name":        connection["name"],
+		"type":        connection["type"],
+		"description": connection["description"],
+	}
+	for _, connectionType := range connectionTypes {
+		values[connectionType] = nil
 	}
 
-	switch connectionType {
-	case "confluent":
-		connection["bootstrapServers"] = d.Get("bootstrap_servers").(string)
-		connection["topicName"] = d.Get("topic_name").(string)
-		connection["topicNameIsPattern"] = d.Get("topic_name_is_pattern").(bool)
-		if v, ok := d.GetOk("secrets"); ok {
-			connection["secrets"] = expandSecrets(v.([]interface{}))
-		}
-	case "kafka":
-		connection["bootstrapServers"] = d.Get("bootstrap_servers").(string)
-		connection["clientRack"] = d.Get("client_rack").(string)
-		if v, ok := d.GetOk("ssl"); ok {
-			connection["ssl"] = expandSSL(v.([]interface{}))
-		}
-		connection["topicName"] = d.Get("topic_name").(string)
-		connection["topicNameIsPattern"] = d.Get("topic_name_is_pattern").(bool)
-		if v, ok := d.GetOk("secrets"); ok {
-			connection["secrets"] = expandSecrets(v.([]interface{}))
+	connectionType, _ := connection["type"].(string)
+	if handler, ok := connectionHandlers[connectionType]; ok {
+		var prior map[string]interface{}
+		if blocks := d.Get(connectionType).([]interface{}); len(blocks) > 0 {
+			prior, _ = blocks[0].(map[string]interface{})
 		}
-	case "kinesis":
-		connection["awsAssumedRoleArn"] = d.Get("aws_assumed_role_arn").(string)
-		connection["awsEndpoint"] = d.Get("aws_endpoint").(string)
-		connection["stream"] = d.Get("stream").(string)
-	case "s3":
-		connection["awsAssumedRoleArn"] = d.Get("aws_assumed_role_arn").(string)
-		connection["awsEndpoint"] = d.Get("aws_endpoint").(string)
-		connection["bucket"] = d.Get("bucket").(string)
-		connection["prefix"] = d.Get("prefix").(string)
-		if v, ok := d.GetOk("secrets"); ok {
-			connection["secrets"] = expandSecrets(v.([]interface{}))
+		values[connectionType] = []interface{}{handler.flatten(connection, prior)}
+	}
+
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return fmt.Errorf("error setting %s: %w", key, err)
 		}
 	}
+	return nil
+}
 
-	url := fmt.Sprintf("/v1/projects/%s/connections/%s", projectID, connectionName)
-	err := client.UpdateConnection(url, connection)
+// resourcePolarisConnectionImport imports a connection by
+// "project_id/connection_name".
+func resourcePolarisConnectionImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	projectID, name, err := parseCompositeID(d.Id(), "project_id/connection_name")
 	if err != nil {
-		return fmt.Errorf("Error updating connection: %s", err)
+		return nil, err
 	}
 
-	return resourcePolarisConnectionRead(d, m)
+	if err := d.Set("project_id", projectID); err != nil {
+		return nil, err
+	}
+	if err := d.Set("name", name); err != nil {
+		return nil, err
+	}
+	d.SetId(compositeID(projectID, name))
+	return []*schema.ResourceData{d}, nil
 }
 
-func resourcePolarisConnectionDelete(d *schema.ResourceData, m interface{}) error {
+func resourcePolarisConnectionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*Client)
-	projectID := d.Get("project_id").(string)
-	connectionName := d.Get("name").(string)
+	projectID, connectionName, err := parseCompositeID(d.Id(), "project_id/connection_name")
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	url := fmt.Sprintf("/v1/projects/%s/connections/%s", projectID, connectionName)
-	err := client.DeleteConnection(url)
+	connection, err := expandConnection(d)
+	if err != nil {
+		return diag.Errorf("Error updating connection: %s", err)
+	}
+
+	if _, err := client.UpdateConnection(ctx, projectID, connectionName, connection); err != nil {
+		return connectionErrorDiagnostics("Error updating connection", err, connection["type"].(string))
+	}
+
+	return resourcePolarisConnectionRead(ctx, d, m)
+}
+
+func resourcePolarisConnectionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID, connectionName, err := parseCompositeID(d.Id(), "project_id/connection_name")
 	if err != nil {
-		return fmt.Errorf("Error deleting connection: %s", err)
+		return diag.FromErr(err)
+	}
+
+	if err := client.DeleteConnection(ctx, projectID, connectionName); err != nil && !IsNotFound(err) {
+		return diag.Errorf("Error deleting connection: %s", err)
 	}
 
 	d.SetId("")
@@ -363,14 +336,13 @@ func expandSecrets(secrets []interface{}) map[string]interface{} {
 }
 
 func expandSSL(ssl []interface{}) map[string]interface{} {
-	if len(ssl) == 0 {
+	if len(ssl) == 0 || ssl[0] == nil {
 		return nil
 	}
 	sslMap := ssl[0].(map[string]interface{})
-	expandedSSL := map[string]interface{}{
-		"truststore": map[string]interface{}{
-			"type": sslMap["truststore"].([]interface{})[0].(map[string]interface{})["type"].(string),
-		},
+	expandedSSL := map[string]interface{}{}
+	if truststore := expandTruststore(sslMap["truststore"].([]interface{})); truststore != nil {
+		expandedSSL["truststore"] = truststore
 	}
 	return expandedSSL
 }
@@ -386,16 +358,24 @@ func expandTruststore(truststore []interface{}) map[string]interface{} {
 	}
 }
 
-func flattenSecrets(secrets map[string]interface{}) []interface{} {
+// flattenSecrets converts the secrets Polaris returns into the secrets block.
+// Polaris never returns secret values, so the password is carried over from
+// prior, the secrets block currently in state.
+func flattenSecrets(secrets map[string]interface{}, prior []interface{}) []interface{} {
 	if secrets == nil {
 		return nil
 	}
 
-	return []interface{}{
-		map[string]interface{}{
-			"type": secrets["type"].(string),
-		},
+	flat := map[string]interface{}{
+		"type":     secrets["type"],
+		"username": secrets["username"],
+	}
+	if len(prior) > 0 && prior[0] != nil {
+		if password, ok := prior[0].(map[string]interface{})["password"]; ok {
+			flat["password"] = password
+		}
 	}
+	return []interface{}{flat}
 }
 
 func flattenSSL(ssl map[string]interface{}) []interface{} {
@@ -403,9 +383,10 @@ func flattenSSL(ssl map[string]interface{}) []interface{} {
 		return nil
 	}
 
+	truststore, _ := ssl["truststore"].(map[string]interface{})
 	return []interface{}{
 		map[string]interface{}{
-			"truststore": flattenTruststore(ssl["truststore"].(map[string]interface{})),
+			"truststore": flattenTruststore(truststore),
 		},
 	}
 }
@@ -417,7 +398,7 @@ func flattenTruststore(truststore map[string]interface{}) []interface{} {
 
 	return []interface{}{
 		map[string]interface{}{
-			"type": truststore["type"].(string),
+			"type": truststore["type"],
 		},
 	}
 }
@@ -0,0 +1,197 @@
+package polaris
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourcePolarisConnectionV0 is the polaris_connection schema before version
+// 1, when the settings of every connection type were top-level attributes. It
+// is only used to decode old states.
+func resourcePolarisConnectionV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"bootstrap_servers": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"client_rack": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ssl": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"truststore": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"topic_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"topic_name_is_pattern": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"secrets": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"password": {
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+						},
+					},
+				},
+			},
+			"aws_assumed_role_arn": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"aws_endpoint": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"stream": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"bucket": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// connectionV0Attributes lists, for each connection type, the top-level
+// attributes of the version 0 schema that belonged to it.
+var connectionV0Attributes = map[string][]string{
+	"confluent": {"bootstrap_servers", "topic_name", "topic_name_is_pattern", "secrets"},
+	"kafka":     {"bootstrap_servers", "client_rack", "ssl", "topic_name", "topic_name_is_pattern", "secrets"},
+	"kinesis":   {"aws_assumed_role_arn", "aws_endpoint", "stream"},
+	"s3":        {"aws_assumed_role_arn", "aws_endpoint", "bucket", "prefix", "secrets"},
+}
+
+// resourcePolarisConnectionStateUpgradeV0 moves the top-level attributes of
+// the connection's type into the block named after the type. Attributes of
+// other types, which Polaris never set, are dropped.
+func resourcePolarisConnectionStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	connectionType, _ := rawState["type"].(string)
+	block := map[string]interface{}{}
+	for _, key := range connectionV0Attributes[connectionType] {
+		block[key] = rawState[key]
+	}
+	for blockType, keys := range connectionV0Attributes {
+		rawState[blockType] = []interface{}{}
+		for _, key := range keys {
+			delete(rawState, key)
+		}
+	}
+	if _, ok := connectionV0Attributes[connectionType]; ok {
+		rawState[connectionType] = []interface{}{block}
+	}
+	return rawState, nil
+}
+
+// resourcePolarisConnectionV1 is the polaris_connection schema at version 1,
+// when the resource ID was the bare connection name. Its kafka, confluent,
+// kinesis and s3 blocks hold the version 0 attributes listed in
+// connectionV0Attributes, so it is derived from version 0.
+func resourcePolarisConnectionV1() *schema.Resource {
+	v0 := resourcePolarisConnectionV0().Schema
+	s := map[string]*schema.Schema{
+		"project_id":  v0["project_id"],
+		"name":        v0["name"],
+		"type":        v0["type"],
+		"description": v0["description"],
+	}
+	for connectionType, keys := range connectionV0Attributes {
+		block := make(map[string]*schema.Schema, len(keys))
+		for _, key := range keys {
+			block[key] = v0[key]
+		}
+		s[connectionType] = &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     &schema.Resource{Schema: block},
+		}
+	}
+	return &schema.Resource{Schema: s}
+}
+
+// resourcePolarisConnectionStateUpgradeV1 replaces the connection name used as
+// the resource ID with "project_id/name".
+func resourcePolarisConnectionStateUpgradeV1(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	id, _ := rawState["id"].(string)
+	if strings.Contains(id, "/") {
+		return rawState, nil
+	}
+	projectID, _ := rawState["project_id"].(string)
+	name, _ := rawState["name"].(string)
+	if projectID == "" || name == "" {
+		return nil, fmt.Errorf("cannot upgrade polaris_connection state without project_id and name")
+	}
+
+	rawState["id"] = compositeID(projectID, name)
+	return rawState, nil
+}
@@ -0,0 +1,135 @@
+package polaris
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourcePolarisConnectionStateUpgradeV0(t *testing.T) {
+	state, err := resourcePolarisConnectionStateUpgradeV0(context.Background(), map[string]interface{}{
+		"id":                    "kafka1",
+		"project_id":            "p",
+		"name":                  "kafka1",
+		"type":                  "kafka",
+		"description":           "events",
+		"bootstrap_servers":     "broker:9092",
+		"client_rack":           "",
+		"ssl":                   []interface{}{},
+		"topic_name":            "events",
+		"topic_name_is_pattern": false,
+		"secrets":               []interface{}{map[string]interface{}{"type": "sasl_plain", "username": "u", "password": "pw"}},
+		"aws_assumed_role_arn":  "",
+		"aws_endpoint":          "",
+		"stream":                "",
+		"bucket":                "",
+		"prefix":                "",
+	}, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	want := map[string]interface{}{
+		"id":          "kafka1",
+		"project_id":  "p",
+		"name":        "kafka1",
+		"type":        "kafka",
+		"description": "events",
+		"kafka": []interface{}{map[string]interface{}{
+			"bootstrap_servers":     "broker:9092",
+			"client_rack":           "",
+			"ssl":                   []interface{}{},
+			"topic_name":            "events",
+			"topic_name_is_pattern": false,
+			"secrets":               []interface{}{map[string]interface{}{"type": "sasl_plain", "username": "u", "password": "pw"}},
+		}},
+		"confluent": []interface{}{},
+		"kinesis":   []interface{}{},
+		"s3":        []interface{}{},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("upgraded state = %#v, want %#v", state, want)
+	}
+}
+
+func TestResourcePolarisConnectionStateUpgradeV0UnknownType(t *testing.T) {
+	state, err := resourcePolarisConnectionStateUpgradeV0(context.Background(), map[string]interface{}{
+		"id":     "c1",
+		"name":   "c1",
+		"type":   "",
+		"bucket": "",
+	}, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	want := map[string]interface{}{
+		"id":        "c1",
+		"name":      "c1",
+		"type":      "",
+		"confluent": []interface{}{},
+		"kafka":     []interface{}{},
+		"kinesis":   []interface{}{},
+		"s3":        []interface{}{},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("upgraded state = %#v, want %#v", state, want)
+	}
+}
+
+func TestResourcePolarisConnectionStateUpgradeV1(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{"kafka1", "p/kafka1", false},
+		{"p/kafka1", "p/kafka1", false},
+	}
+	for _, tt := range tests {
+		state, err := resourcePolarisConnectionStateUpgradeV1(context.Background(), map[string]interface{}{
+			"id":         tt.id,
+			"project_id": "p",
+			"name":       "kafka1",
+		}, nil)
+		if err != nil {
+			t.Fatalf("upgrade %q: %s", tt.id, err)
+		}
+		if got := state["id"]; got != tt.want {
+			t.Errorf("upgrade %q: id = %#v, want %q", tt.id, got, tt.want)
+		}
+	}
+
+	if _, err := resourcePolarisConnectionStateUpgradeV1(context.Background(), map[string]interface{}{"id": "kafka1", "name": "kafka1"}, nil); err == nil {
+		t.Error("upgrade succeeded without project_id")
+	}
+}
+
+func TestResourcePolarisConnectionV1Schema(t *testing.T) {
+	// Version 1 predates every connection type added after kafka,
+	// confluent, kinesis and s3; later schema changes must not leak into it.
+	var got []string
+	for name := range resourcePolarisConnectionV1().Schema {
+		got = append(got, name)
+	}
+	sort.Strings(got)
+	want := []string{"confluent", "description", "kafka", "kinesis", "name", "project_id", "s3", "type"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("version 1 attributes = %q, want %q", got, want)
+	}
+
+	kafka := resourcePolarisConnectionV1().Schema["kafka"].Elem.(*schema.Resource).Schema
+	if _, ok := kafka["secrets"]; !ok {
+		t.Error("version 1 kafka block has no secrets")
+	}
+}
+
+func TestResourcePolarisConnectionStateUpgradeNil(t *testing.T) {
+	for i, upgrade := range resourcePolarisConnection().StateUpgraders {
+		state, err := upgrade.Upgrade(context.Background(), nil, nil)
+		if state != nil || err != nil {
+			t.Errorf("upgrader V%d(nil) = %#v, %v, want nil, nil", i, state, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package polaris
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestResourcePolarisConnection(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	kafka := func(topic string) string {
+		return testProviderConfig(srv) + fmt.Sprintf(`
+resource "polaris_connection" "events" {
+  project_id = "p"
+  name       = "events"
+
+  kafka {
+    bootstrap_servers = "broker:9092"
+    topic_name        = %q
+
+    secrets {
+      type     = "sasl_plain"
+      username = "user"
+      password = "secret"
+    }
+  }
+}
+`, topic)
+	}
+	kinesis := testProviderConfig(srv) + `
+resource "polaris_connection" "events" {
+  project_id = "p"
+  name       = "events"
+
+  kinesis {
+    aws_assumed_role_arn = "arn:aws:iam::123456789012:role/polaris"
+    aws_endpoint         = "kinesis.us-east-1.amazonaws.com"
+    stream               = "events"
+  }
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckPolarisConnectionDestroyed(srv, "p", "events"),
+		Steps: []resource.TestStep{
+			{
+				Config: kafka("events"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_connection.events", "id", "p/events"),
+					resource.TestCheckResourceAttr("polaris_connection.events", "type", "kafka"),
+					resource.TestCheckResourceAttr("polaris_connection.events", "kafka.0.secrets.0.username", "user"),
+					resource.TestCheckResourceAttr("polaris_connection.events", "kafka.0.secrets.0.password", "secret"),
+					testCheckPolarisConnection(srv, "p", "events", "topicName", "events"),
+				),
+			},
+			{
+				Config: kafka("clicks"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_connection.events", "kafka.0.topic_name", "clicks"),
+					testCheckPolarisConnection(srv, "p", "events", "topicName", "clicks"),
+				),
+			},
+			{
+				ResourceName:      "polaris_connection.events",
+				ImportState:       true,
+				ImportStateId:     "p/events",
+				ImportStateVerify: true,
+				// Polaris never returns secret values.
+				ImportStateVerifyIgnore: []string{"kafka.0.secrets.0.password"},
+			},
+			{
+				// Changing the type replaces the connection.
+				Config: kinesis,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_connection.events", "type", "kinesis"),
+					resource.TestCheckResourceAttr("polaris_connection.events", "kafka.#", "0"),
+					testCheckPolarisConnection(srv, "p", "events", "type", "kinesis"),
+					testCheckPolarisConnection(srv, "p", "events", "stream", "events"),
+				),
+			},
+			{
+				ResourceName:      "polaris_connection.events",
+				ImportState:       true,
+				ImportStateId:     "p/events",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourcePolarisConnectionImport(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutConnection("p", map[string]interface{}{
+		"name":        "bucket",
+		"type":        "s3",
+		"description": "created elsewhere",
+		"bucket":      "raw-events",
+		"prefix":      "events/",
+		"awsEndpoint": "s3.us-east-1.amazonaws.com",
+	})
+
+	config := testProviderConfig(srv) + `
+resource "polaris_connection" "bucket" {
+  project_id  = "p"
+  name        = "bucket"
+  description = "created elsewhere"
+
+  s3 {
+    bucket       = "raw-events"
+    prefix       = "events/"
+    aws_endpoint = "s3.us-east-1.amazonaws.com"
+  }
+}
+`
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				ResourceName:       "polaris_connection.bucket",
+				ImportState:        true,
+				ImportStateId:      "p/bucket",
+				ImportStatePersist: true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("imported %d resources, want 1", len(states))
+					}
+					attrs := states[0].Attributes
+					for key, want := range map[string]string{
+						"id":          "p/bucket",
+						"project_id":  "p",
+						"name":        "bucket",
+						"type":        "s3",
+						"s3.0.bucket": "raw-events",
+						"s3.0.prefix": "events/",
+					} {
+						if attrs[key] != want {
+							return fmt.Errorf("%s = %q, want %q", key, attrs[key], want)
+						}
+					}
+					return nil
+				},
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+
+	if _, ok := srv.Connection("p", "bucket"); ok {
+		t.Error("connection still exists after destroy")
+	}
+}
+
+func TestResourcePolarisConnectionImportRejectsBareName(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutConnection("p", map[string]interface{}{"name": "events", "type": "kinesis"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "polaris_connection" "events" {
+  project_id = "p"
+  name       = "events"
+
+  kinesis {
+    aws_assumed_role_arn = "arn:aws:iam::123456789012:role/polaris"
+    aws_endpoint         = "kinesis.us-east-1.amazonaws.com"
+    stream               = "events"
+  }
+}
+`,
+				ResourceName:  "polaris_connection.events",
+				ImportState:   true,
+				ImportStateId: "events",
+				ExpectError:   regexp.MustCompile(`project_id/connection_name`),
+			},
+		},
+	})
+}
+
+// testCheckPolarisConnection checks a field of the connection as stored by
+// the fake Polaris API.
+
+// testCheckPolarisConnection checks a field of the connection as stored by
+// the fake Polaris API.
+func testCheckPolarisConnection(srv *polaristest.Server, projectID, name, field string, want interface{}) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		connection, ok := srv.Connection(projectID, name)
+		if !ok {
+			return fmt.Errorf("connection %s/%s does not exist", projectID, name)
+		}
+		if connection[field] != want {
+			return fmt.Errorf("connection %s/%s: %s = %#v, want %#v", projectID, name, field, connection[field], want)
+		}
+		return nil
+	}
+}
+
+func testCheckPolarisConnectionDestroyed(srv *polaristest.Server, projectID, name string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if _, ok := srv.Connection(projectID, name); ok {
+			return fmt.Errorf("connection %s/%s still exists", projectID, name)
+		}
+		return nil
+	}
+}
+
+func TestResourcePolarisConnectionReplacement(t *testing.T) {
+	kafka := []interface{}{map[string]interface{}{
+		"bootstrap_servers": "broker:9092",
+		"topic_name":        "events",
+	}}
+	prior := map[string]interface{}{
+		"project_id":  "p",
+		"name":        "events",
+		"type":        "kafka",
+		"description": "Event stream",
+		"kafka":       kafka,
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		requiresNew bool
+	}{
+		{
+			name:        "project_id",
+			config:      map[string]interface{}{"project_id": "other", "name": "events", "kafka": kafka},
+			requiresNew: true,
+		},
+		{
+			name:        "name",
+			config:      map[string]interface{}{"project_id": "p", "name": "clicks", "kafka": kafka},
+			requiresNew: true,
+		},
+		{
+			name: "kafka to s3",
+			config: map[string]interface{}{
+				"project_id": "p",
+				"name":       "events",
+				"s3": []interface{}{map[string]interface{}{
+					"bucket":       "events-bucket",
+					"aws_endpoint": "s3.us-east-1.amazonaws.com",
+				}},
+			},
+			requiresNew: true,
+		},
+		{
+			name:   "description",
+			config: map[string]interface{}{"project_id": "p", "name": "events", "description": "Clicks", "kafka": kafka},
+		},
+		{
+			name: "kafka settings",
+			config: map[string]interface{}{"project_id": "p", "name": "events", "kafka": []interface{}{map[string]interface{}{
+				"bootstrap_servers": "broker:9092",
+				"topic_name":        "clicks",
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := testPlanChange(t, resourcePolarisConnection(), prior, tt.config)
+			if diff.Empty() {
+				t.Fatal("changing the configuration planned no diff")
+			}
+			if got := diff.RequiresNew(); got != tt.requiresNew {
+				t.Errorf("RequiresNew = %t, want %t; diff: %#v", got, tt.requiresNew, diff.Attributes)
+			}
+		})
+	}
+
+	// The new type is planned along with the replacement.
+	diff := testPlanChange(t, resourcePolarisConnection(), prior, tests[2].config)
+	if got := diff.Attributes["type"]; got == nil || got.Old != "kafka" || got.New != "s3" || !got.RequiresNew {
+		t.Errorf("type diff = %#v, want kafka to s3 requiring replacement", got)
+	}
+}
@@ -0,0 +1,728 @@
+package polaris
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// connectionHandler describes one connection type: the attributes of its
+// block in polaris_connection and how that block maps to and from the fields
+// of a Polaris connection.
+type connectionHandler struct {
+	// schema returns the attributes of the type's block.
+	schema func() map[string]*schema.Schema
+	// expand copies the attributes of block into connection, the request body.
+	expand func(block, connection map[string]interface{})
+	// flatten builds the type's block from connection as Polaris returns it.
+	// prior is the block currently in state, if any, and supplies the values
+	// Polaris never returns, such as passwords.
+	flatten func(connection, prior map[string]interface{}) map[string]interface{}
+	// validate, if set, checks the block at the attribute path block for
+	// mistakes the schema cannot express. Values unknown during the plan are
+	// skipped.
+	validate func(d *schema.ResourceDiff, block string) []error
+}
+
+// connectionHandlers holds the handler of every connection type, keyed by the
+// Polaris type name. Each type is configured with a block of the same name.
+var connectionHandlers = map[string]connectionHandler{
+	"azure": {
+		schema:   azureConnectionSchema,
+		expand:   expandAzureConnection,
+		flatten:  flattenAzureConnection,
+		validate: validateAzureConnection,
+	},
+	"azure_event_hubs": {
+		schema:   azureEventHubsConnectionSchema,
+		expand:   expandAzureEventHubsConnection,
+		flatten:  flattenAzureEventHubsConnection,
+		validate: validateAzureEventHubsConnection,
+	},
+	"confluent": {
+		schema:  confluentConnectionSchema,
+		expand:  expandConfluentConnection,
+		flatten: flattenConfluentConnection,
+	},
+	"confluent_schema_registry": {
+		schema:  confluentSchemaRegistryConnectionSchema,
+		expand:  expandConfluentSchemaRegistryConnection,
+		flatten: flattenConfluentSchemaRegistryConnection,
+	},
+	"gcs": {
+		schema:  gcsConnectionSchema,
+		expand:  expandGCSConnection,
+		flatten: flattenGCSConnection,
+	},
+	"kafka": {
+		schema:  kafkaConnectionSchema,
+		expand:  expandKafkaConnection,
+		flatten: flattenKafkaConnection,
+	},
+	"kinesis": {
+		schema:  kinesisConnectionSchema,
+		expand:  expandKinesisConnection,
+		flatten: flattenKinesisConnection,
+	},
+	"s3": {
+		schema:  s3ConnectionSchema,
+		expand:  expandS3Connection,
+		flatten: flattenS3Connection,
+	},
+}
+
+// connectionTypes are the kinds of connection Polaris supports, sorted.
+var connectionTypes = slices.Sorted(maps.Keys(connectionHandlers))
+
+var (
+	// azureStorageAccountPattern matches Azure storage account names: 3 to 24
+	// lowercase letters and digits.
+	azureStorageAccountPattern = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+	// azureContainerPattern matches Azure container names: lowercase letters,
+	// digits and single hyphens, starting and ending with a letter or digit.
+	azureContainerPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// azureSecretFields maps each type of Azure secrets to the attributes it
+// requires.
+var azureSecretFields = map[string][]string{
+	"azure_key":               {"key"},
+	"azure_sas_token":         {"sas_token"},
+	"azure_service_principal": {"tenant_id", "client_id", "client_secret"},
+}
+
+// azureSecretAPIFields maps the attributes of Azure secrets to their API
+// fields.
+var azureSecretAPIFields = map[string]string{
+	"key":           "key",
+	"sas_token":     "sasToken",
+	"tenant_id":     "tenantId",
+	"client_id":     "clientId",
+	"client_secret": "clientSecret",
+}
+
+func azureConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"storage_account": {
+			Type:     schema.TypeString,
+			Required: true,
+			ValidateFunc: validation.StringMatch(azureStorageAccountPattern,
+				"must be 3 to 24 lowercase letters and digits"),
+		},
+		"container": {
+			Type:     schema.TypeString,
+			Required: true,
+			ValidateFunc: validation.All(
+				validation.StringLenBetween(3, 63),
+				validation.StringMatch(azureContainerPattern,
+					"must be lowercase letters, digits and single hyphens, starting and ending with a letter or digit"),
+			),
+		},
+		"prefix": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"secrets": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice(slices.Sorted(maps.Keys(azureSecretFields)), false),
+					},
+					"key": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"sas_token": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"tenant_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"client_id": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"client_secret": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func expandAzureConnection(block, connection map[string]interface{}) {
+	connection["storageAccount"] = block["storage_account"].(string)
+	connection["container"] = block["container"].(string)
+	connection["prefix"] = block["prefix"].(string)
+	if secrets := expandTypedSecrets(block["secrets"].([]interface{}), azureSecretAPIFields); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+// flattenAzureConnection builds the azure block. Polaris returns the tenant
+// and client IDs of a service principal, but never the key, SAS token or
+// client secret, so those are carried over from prior.
+func flattenAzureConnection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	return map[string]interface{}{
+		"storage_account": connection["storageAccount"],
+		"container":       connection["container"],
+		"prefix":          connection["prefix"],
+		"secrets":         flattenTypedSecrets(secrets, priorSecrets, azureSecretAPIFields, "key", "sas_token", "client_secret"),
+	}
+}
+
+func validateAzureConnection(d *schema.ResourceDiff, block string) []error {
+	return validateConnectionSecrets(d, block+".secrets.0", azureSecretFields)
+}
+
+var (
+	// eventHubsNamespacePattern matches Event Hubs namespace names: 6 to 50
+	// letters, digits and hyphens, starting with a letter and ending with a
+	// letter or digit.
+	eventHubsNamespacePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{4,48}[a-zA-Z0-9]$`)
+
+	// eventHubNamePattern matches event hub names: letters, digits, periods,
+	// hyphens and underscores, starting and ending with a letter or digit.
+	eventHubNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]*[a-zA-Z0-9])?$`)
+)
+
+// eventHubsSecretFields maps each type of Event Hubs secrets to the attributes
+// it requires.
+var eventHubsSecretFields = map[string][]string{
+	"azure_connection_string": {"connection_string"},
+	"azure_sas_key":           {"sas_key_name", "sas_key"},
+}
+
+// eventHubsSecretAPIFields maps the attributes of Event Hubs secrets to their
+// API fields.
+var eventHubsSecretAPIFields = map[string]string{
+	"connection_string": "connectionString",
+	"sas_key_name":      "sasKeyName",
+	"sas_key":           "sasKey",
+}
+
+func azureEventHubsConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"namespace": {
+			Type:     schema.TypeString,
+			Required: true,
+			ValidateFunc: validation.StringMatch(eventHubsNamespacePattern,
+				"must be 6 to 50 letters, digits and hyphens, starting with a letter and ending with a letter or digit"),
+		},
+		"event_hub_name": {
+			Type:     schema.TypeString,
+			Required: true,
+			ValidateFunc: validation.All(
+				validation.StringLenBetween(1, 256),
+				validation.StringMatch(eventHubNamePattern,
+					"must be letters, digits, periods, hyphens and underscores, starting and ending with a letter or digit"),
+			),
+		},
+		"consumer_group": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "$Default",
+			ValidateFunc: validation.StringLenBetween(1, 50),
+		},
+		"secrets": {
+			Type:     schema.TypeList,
+			Required: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice(slices.Sorted(maps.Keys(eventHubsSecretFields)), false),
+					},
+					"connection_string": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"sas_key_name": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"sas_key": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func expandAzureEventHubsConnection(block, connection map[string]interface{}) {
+	connection["namespace"] = block["namespace"].(string)
+	connection["eventHubName"] = block["event_hub_name"].(string)
+	connection["consumerGroup"] = block["consumer_group"].(string)
+	if secrets := expandTypedSecrets(block["secrets"].([]interface{}), eventHubsSecretAPIFields); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+// flattenAzureEventHubsConnection builds the azure_event_hubs block. Polaris
+// returns the SAS key name but never the connection string or SAS key, so
+// those are carried over from prior.
+func flattenAzureEventHubsConnection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	return map[string]interface{}{
+		"namespace":      connection["namespace"],
+		"event_hub_name": connection["eventHubName"],
+		"consumer_group": connection["consumerGroup"],
+		"secrets":        flattenTypedSecrets(secrets, priorSecrets, eventHubsSecretAPIFields, "connection_string", "sas_key"),
+	}
+}
+
+func validateAzureEventHubsConnection(d *schema.ResourceDiff, block string) []error {
+	return validateConnectionSecrets(d, block+".secrets.0", eventHubsSecretFields)
+}
+
+func confluentConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"bootstrap_servers": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"topic_name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"topic_name_is_pattern": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"secrets": connectionSecretsSchema(),
+	}
+}
+
+func expandConfluentConnection(block, connection map[string]interface{}) {
+	connection["bootstrapServers"] = block["bootstrap_servers"].(string)
+	connection["topicName"] = block["topic_name"].(string)
+	connection["topicNameIsPattern"] = block["topic_name_is_pattern"].(bool)
+	if secrets := expandSecrets(block["secrets"].([]interface{})); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+func flattenConfluentConnection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	return map[string]interface{}{
+		"bootstrap_servers":     connection["bootstrapServers"],
+		"topic_name":            connection["topicName"],
+		"topic_name_is_pattern": connection["topicNameIsPattern"],
+		"secrets":               flattenSecrets(secrets, priorSecrets),
+	}
+}
+
+// schemaRegistrySecretAPIFields maps the attributes of schema registry
+// secrets to their API fields.
+var schemaRegistrySecretAPIFields = map[string]string{
+	"api_key":    "apiKey",
+	"api_secret": "apiSecret",
+}
+
+func confluentSchemaRegistryConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"urls": {
+			Type:     schema.TypeList,
+			Required: true,
+			MinItems: 1,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+		},
+		"secrets": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice([]string{"basic_auth"}, false),
+					},
+					"api_key": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"api_secret": {
+						Type:      schema.TypeString,
+						Required:  true,
+						Sensitive: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func expandConfluentSchemaRegistryConnection(block, connection map[string]interface{}) {
+	var urls []string
+	for _, url := range block["urls"].([]interface{}) {
+		if url, ok := url.(string); ok {
+			urls = append(urls, url)
+		}
+	}
+	connection["urls"] = urls
+	if secrets := expandTypedSecrets(block["secrets"].([]interface{}), schemaRegistrySecretAPIFields); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+// flattenConfluentSchemaRegistryConnection builds the
+// confluent_schema_registry block. Polaris returns the API key but never the
+// API secret, so it is carried over from prior.
+func flattenConfluentSchemaRegistryConnection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	return map[string]interface{}{
+		"urls":    connection["urls"],
+		"secrets": flattenTypedSecrets(secrets, priorSecrets, schemaRegistrySecretAPIFields, "api_secret"),
+	}
+}
+
+// gcsBucketPattern matches Google Cloud Storage bucket names: lowercase
+// letters, digits, dashes, underscores and dots, starting and ending with a
+// letter or digit.
+var gcsBucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*[a-z0-9]$`)
+
+// gcsSecretAPIFields maps the attributes of GCS secrets to their API fields.
+var gcsSecretAPIFields = map[string]string{
+	"key": "key",
+}
+
+func gcsConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"bucket": {
+			Type:     schema.TypeString,
+			Required: true,
+			ValidateFunc: validation.All(
+				validation.StringLenBetween(3, 222),
+				validation.StringMatch(gcsBucketPattern,
+					"must be lowercase letters, digits, dashes, underscores and dots, starting and ending with a letter or digit"),
+			),
+		},
+		"prefix": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"secrets": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice([]string{"gcs_service_account_key"}, false),
+					},
+					"key": {
+						Type:         schema.TypeString,
+						Required:     true,
+						Sensitive:    true,
+						ValidateFunc: validation.StringIsJSON,
+					},
+				},
+			},
+		},
+	}
+}
+
+func expandGCSConnection(block, connection map[string]interface{}) {
+	connection["bucket"] = block["bucket"].(string)
+	connection["prefix"] = block["prefix"].(string)
+	if secrets := expandTypedSecrets(block["secrets"].([]interface{}), gcsSecretAPIFields); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+// flattenGCSConnection builds the gcs block. Polaris never returns the service
+// account key, so it is carried over from prior.
+func flattenGCSConnection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	return map[string]interface{}{
+		"bucket":  connection["bucket"],
+		"prefix":  connection["prefix"],
+		"secrets": flattenTypedSecrets(secrets, priorSecrets, gcsSecretAPIFields, "key"),
+	}
+}
+
+func kafkaConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"bootstrap_servers": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"client_rack": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"ssl": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"truststore": {
+						Type:     schema.TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"type": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"topic_name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"topic_name_is_pattern": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"secrets": connectionSecretsSchema(),
+	}
+}
+
+func expandKafkaConnection(block, connection map[string]interface{}) {
+	connection["bootstrapServers"] = block["bootstrap_servers"].(string)
+	connection["clientRack"] = block["client_rack"].(string)
+	if ssl := expandSSL(block["ssl"].([]interface{})); ssl != nil {
+		connection["ssl"] = ssl
+	}
+	connection["topicName"] = block["topic_name"].(string)
+	connection["topicNameIsPattern"] = block["topic_name_is_pattern"].(bool)
+	if secrets := expandSecrets(block["secrets"].([]interface{})); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+func flattenKafkaConnection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	ssl, _ := connection["ssl"].(map[string]interface{})
+	return map[string]interface{}{
+		"bootstrap_servers":     connection["bootstrapServers"],
+		"client_rack":           connection["clientRack"],
+		"ssl":                   flattenSSL(ssl),
+		"topic_name":            connection["topicName"],
+		"topic_name_is_pattern": connection["topicNameIsPattern"],
+		"secrets":               flattenSecrets(secrets, priorSecrets),
+	}
+}
+
+func kinesisConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"aws_assumed_role_arn": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"aws_endpoint": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"stream": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+	}
+}
+
+func expandKinesisConnection(block, connection map[string]interface{}) {
+	connection["awsAssumedRoleArn"] = block["aws_assumed_role_arn"].(string)
+	connection["awsEndpoint"] = block["aws_endpoint"].(string)
+	connection["stream"] = block["stream"].(string)
+}
+
+func flattenKinesisConnection(connection, _ map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"aws_assumed_role_arn": connection["awsAssumedRoleArn"],
+		"aws_endpoint":         connection["awsEndpoint"],
+		"stream":               connection["stream"],
+	}
+}
+
+func s3ConnectionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"bucket": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"prefix": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"aws_assumed_role_arn": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"aws_endpoint": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"secrets": connectionSecretsSchema(),
+	}
+}
+
+func expandS3Connection(block, connection map[string]interface{}) {
+	connection["awsAssumedRoleArn"] = block["aws_assumed_role_arn"].(string)
+	connection["awsEndpoint"] = block["aws_endpoint"].(string)
+	connection["bucket"] = block["bucket"].(string)
+	connection["prefix"] = block["prefix"].(string)
+	if secrets := expandSecrets(block["secrets"].([]interface{})); secrets != nil {
+		connection["secrets"] = secrets
+	}
+}
+
+func flattenS3Connection(connection, prior map[string]interface{}) map[string]interface{} {
+	priorSecrets, _ := prior["secrets"].([]interface{})
+	secrets, _ := connection["secrets"].(map[string]interface{})
+	return map[string]interface{}{
+		"bucket":               connection["bucket"],
+		"prefix":               connection["prefix"],
+		"aws_assumed_role_arn": connection["awsAssumedRoleArn"],
+		"aws_endpoint":         connection["awsEndpoint"],
+		"secrets":              flattenSecrets(secrets, priorSecrets),
+	}
+}
+
+// connectionSecretsSchema is the secrets block shared by the connection types
+// that authenticate with a username and password.
+func connectionSecretsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"username": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"password": {
+					Type:      schema.TypeString,
+					Optional:  true,
+					Sensitive: true,
+				},
+			},
+		},
+	}
+}
+
+// expandTypedSecrets builds the secrets of a connection from a secrets block
+// with a type and the attributes in fields, which maps each attribute to its
+// API field. Attributes left empty are omitted.
+func expandTypedSecrets(secrets []interface{}, fields map[string]string) map[string]interface{} {
+	if len(secrets) == 0 || secrets[0] == nil {
+		return nil
+	}
+
+	raw := secrets[0].(map[string]interface{})
+	expanded := map[string]interface{}{
+		"type": raw["type"].(string),
+	}
+	for attr, field := range fields {
+		if v, _ := raw[attr].(string); v != "" {
+			expanded[field] = v
+		}
+	}
+	return expanded
+}
+
+// flattenTypedSecrets is the reverse of expandTypedSecrets. Polaris never
+// returns the values of the writeOnly attributes, so they are carried over
+// from prior, the secrets block currently in state.
+func flattenTypedSecrets(secrets map[string]interface{}, prior []interface{}, fields map[string]string, writeOnly ...string) []interface{} {
+	if secrets == nil {
+		return nil
+	}
+
+	flat := map[string]interface{}{
+		"type": secrets["type"],
+	}
+	for attr, field := range fields {
+		if !slices.Contains(writeOnly, attr) {
+			flat[attr] = secrets[field]
+		}
+	}
+	if len(prior) > 0 && prior[0] != nil {
+		for _, attr := range writeOnly {
+			if v, ok := prior[0].(map[string]interface{})[attr]; ok {
+				flat[attr] = v
+			}
+		}
+	}
+	return []interface{}{flat}
+}
+
+// validateConnectionSecrets checks that the secrets block at path sets every
+// attribute its type requires and none that only other types use. fields maps
+// each secrets type to the attributes it requires.
+func validateConnectionSecrets(d *schema.ResourceDiff, path string, fields map[string][]string) []error {
+	if !d.NewValueKnown(path + ".type") {
+		return nil
+	}
+	secretType, _ := d.Get(path + ".type").(string)
+	required, ok := fields[secretType]
+	if !ok {
+		return nil
+	}
+
+	// "azure.0.secrets.0" is reported as "azure secrets".
+	label := strings.ReplaceAll(strings.TrimSuffix(path, ".0"), ".0.", " ")
+	var errs []error
+	for _, otherType := range slices.Sorted(maps.Keys(fields)) {
+		for _, attr := range fields[otherType] {
+			key := path + "." + attr
+			set := d.Get(key).(string) != ""
+			switch {
+			case otherType == secretType && !set && d.NewValueKnown(key):
+				errs = append(errs, fmt.Errorf("%s: %s is required when type is %q", label, attr, secretType))
+			case otherType != secretType && set && !slices.Contains(required, attr):
+				errs = append(errs, fmt.Errorf("%s: %s cannot be set when type is %q", label, attr, secretType))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,442 @@
+package polaris
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testConnectionDiff plans a new polaris_connection configured with the given
+// type block and returns the error reported by its CustomizeDiff.
+func testConnectionDiff(connectionType string, block map[string]interface{}) error {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"project_id":   "p",
+		"name":         "c",
+		connectionType: []interface{}{block},
+	})
+	_, err := resourcePolarisConnection().Diff(context.Background(), nil, config, nil)
+	return err
+}
+
+// testConnectionValidate validates a polaris_connection configured with the
+// given type block, as terraform validate and plan do, and returns the
+// messages of the errors found.
+func testConnectionValidate(connectionType string, block map[string]interface{}) []string {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"project_id":   "p",
+		"name":         "c",
+		connectionType: []interface{}{block},
+	})
+	var errs []string
+	for _, d := range resourcePolarisConnection().Validate(config) {
+		errs = append(errs, d.Summary+": "+d.Detail)
+	}
+	return errs
+}
+
+// testCheckErrors checks that err reports every message in want, or that it
+// is nil when want is empty.
+func testCheckErrors(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Errorf("no error, want %q", want)
+		return
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error %q does not contain %q", err, w)
+		}
+	}
+}
+
+func testAzureBlock(secrets map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"storage_account": "account1",
+		"container":       "events",
+		"secrets":         []interface{}{secrets},
+	}
+}
+
+func TestValidateAzureConnection(t *testing.T) {
+	tests := []struct {
+		name     string
+		secrets  map[string]interface{}
+		wantErrs []string
+	}{
+		{"key", map[string]interface{}{"type": "azure_key", "key": "k"}, nil},
+		{"sas token", map[string]interface{}{"type": "azure_sas_token", "sas_token": "t"}, nil},
+		{
+			name:    "service principal",
+			secrets: map[string]interface{}{"type": "azure_service_principal", "tenant_id": "tenant", "client_id": "client", "client_secret": "s"},
+		},
+		{
+			name:     "missing key",
+			secrets:  map[string]interface{}{"type": "azure_key"},
+			wantErrs: []string{`azure secrets: key is required when type is "azure_key"`},
+		},
+		{
+			name:     "missing client_secret",
+			secrets:  map[string]interface{}{"type": "azure_service_principal", "tenant_id": "tenant", "client_id": "client"},
+			wantErrs: []string{`azure secrets: client_secret is required when type is "azure_service_principal"`},
+		},
+		{
+			name:    "fields of another type",
+			secrets: map[string]interface{}{"type": "azure_sas_token", "sas_token": "t", "key": "k", "tenant_id": "tenant"},
+			wantErrs: []string{
+				`azure secrets: key cannot be set when type is "azure_sas_token"`,
+				`azure secrets: tenant_id cannot be set when type is "azure_sas_token"`,
+			},
+		},
+		{
+			name:    "unknown secret value",
+			secrets: map[string]interface{}{"type": "azure_key", "key": testUnknownValue},
+		},
+		{
+			name:    "unknown type",
+			secrets: map[string]interface{}{"type": testUnknownValue, "key": "k", "sas_token": "t"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCheckErrors(t, testConnectionDiff("azure", testAzureBlock(tt.secrets)), tt.wantErrs)
+		})
+	}
+}
+
+func TestAzureConnectionSchemaValidation(t *testing.T) {
+	secrets := map[string]interface{}{"type": "azure_key", "key": "k"}
+	if errs := testConnectionValidate("azure", testAzureBlock(secrets)); len(errs) > 0 {
+		t.Errorf("valid azure block: %q", errs)
+	}
+
+	block := testAzureBlock(secrets)
+	block["storage_account"] = "Not_Valid"
+	block["container"] = "-events"
+	if errs := testConnectionValidate("azure", block); len(errs) != 2 {
+		t.Errorf("invalid storage_account and container: got %q, want 2 errors", errs)
+	}
+}
+
+func TestExpandAzureConnection(t *testing.T) {
+	connection := map[string]interface{}{}
+	expandAzureConnection(map[string]interface{}{
+		"storage_account": "account1",
+		"container":       "events",
+		"prefix":          "raw/",
+		"secrets": []interface{}{map[string]interface{}{
+			"type":          "azure_sas_token",
+			"key":           "",
+			"sas_token":     "t",
+			"tenant_id":     "",
+			"client_id":     "",
+			"client_secret": "",
+		}},
+	}, connection)
+
+	want := map[string]interface{}{
+		"storageAccount": "account1",
+		"container":      "events",
+		"prefix":         "raw/",
+		"secrets":        map[string]interface{}{"type": "azure_sas_token", "sasToken": "t"},
+	}
+	if !reflect.DeepEqual(connection, want) {
+		t.Errorf("connection = %#v, want %#v", connection, want)
+	}
+}
+
+func TestFlattenAzureConnection(t *testing.T) {
+	// Polaris returns the service principal's IDs but never its secret.
+	connection := map[string]interface{}{
+		"storageAccount": "account1",
+		"container":      "events",
+		"prefix":         "raw/",
+		"secrets":        map[string]interface{}{"type": "azure_service_principal", "tenantId": "tenant", "clientId": "client"},
+	}
+	prior := map[string]interface{}{
+		"secrets": []interface{}{map[string]interface{}{
+			"type":          "azure_service_principal",
+			"key":           "",
+			"sas_token":     "",
+			"tenant_id":     "old-tenant",
+			"client_id":     "old-client",
+			"client_secret": "s",
+		}},
+	}
+
+	got := flattenAzureConnection(connection, prior)
+	want := map[string]interface{}{
+		"storage_account": "account1",
+		"container":       "events",
+		"prefix":          "raw/",
+		"secrets": []interface{}{map[string]interface{}{
+			"type":          "azure_service_principal",
+			"key":           "",
+			"sas_token":     "",
+			"tenant_id":     "tenant",
+			"client_id":     "client",
+			"client_secret": "s",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattened = %#v, want %#v", got, want)
+	}
+
+	// On import there is no prior state to carry secrets over from.
+	got = flattenAzureConnection(connection, nil)
+	secrets := got["secrets"].([]interface{})[0].(map[string]interface{})
+	if _, ok := secrets["client_secret"]; ok {
+		t.Errorf("client_secret = %#v without prior state, want unset", secrets["client_secret"])
+	}
+}
+
+func TestGCSConnectionKeyValidation(t *testing.T) {
+	block := func(key string) map[string]interface{} {
+		return map[string]interface{}{
+			"bucket":  "events-bucket",
+			"secrets": []interface{}{map[string]interface{}{"type": "gcs_service_account_key", "key": key}},
+		}
+	}
+
+	if errs := testConnectionValidate("gcs", block(`{"type": "service_account"}`)); len(errs) > 0 {
+		t.Errorf("JSON key: %q", errs)
+	}
+	errs := testConnectionValidate("gcs", block("not json"))
+	if len(errs) != 1 || !strings.Contains(errs[0], "key") {
+		t.Errorf("non-JSON key: got %q, want one error about key", errs)
+	}
+}
+
+func TestGCSConnectionRoundTrip(t *testing.T) {
+	block := map[string]interface{}{
+		"bucket": "events-bucket",
+		"prefix": "raw/",
+		"secrets": []interface{}{map[string]interface{}{
+			"type": "gcs_service_account_key",
+			"key":  `{"type": "service_account"}`,
+		}},
+	}
+
+	connection := map[string]interface{}{}
+	expandGCSConnection(block, connection)
+	want := map[string]interface{}{
+		"bucket":  "events-bucket",
+		"prefix":  "raw/",
+		"secrets": map[string]interface{}{"type": "gcs_service_account_key", "key": `{"type": "service_account"}`},
+	}
+	if !reflect.DeepEqual(connection, want) {
+		t.Fatalf("expanded = %#v, want %#v", connection, want)
+	}
+
+	// Polaris reads the connection back without the key.
+	connection["secrets"] = map[string]interface{}{"type": "gcs_service_account_key"}
+	if got := flattenGCSConnection(connection, block); !reflect.DeepEqual(got, block) {
+		t.Errorf("flattened = %#v, want %#v", got, block)
+	}
+}
+
+func testEventHubsBlock(secrets map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"namespace":      "events-ns",
+		"event_hub_name": "events",
+		"secrets":        []interface{}{secrets},
+	}
+}
+
+func TestValidateAzureEventHubsConnection(t *testing.T) {
+	tests := []struct {
+		name     string
+		secrets  map[string]interface{}
+		wantErrs []string
+	}{
+		{
+			name:    "connection string",
+			secrets: map[string]interface{}{"type": "azure_connection_string", "connection_string": "Endpoint=sb://events-ns/"},
+		},
+		{
+			name:    "sas key",
+			secrets: map[string]interface{}{"type": "azure_sas_key", "sas_key_name": "listen", "sas_key": "k"},
+		},
+		{
+			name:     "missing connection_string",
+			secrets:  map[string]interface{}{"type": "azure_connection_string"},
+			wantErrs: []string{`azure_event_hubs secrets: connection_string is required when type is "azure_connection_string"`},
+		},
+		{
+			name:     "missing sas_key",
+			secrets:  map[string]interface{}{"type": "azure_sas_key", "sas_key_name": "listen"},
+			wantErrs: []string{`azure_event_hubs secrets: sas_key is required when type is "azure_sas_key"`},
+		},
+		{
+			name:     "sas key with connection string",
+			secrets:  map[string]interface{}{"type": "azure_sas_key", "sas_key_name": "listen", "sas_key": "k", "connection_string": "Endpoint=sb://events-ns/"},
+			wantErrs: []string{`azure_event_hubs secrets: connection_string cannot be set when type is "azure_sas_key"`},
+		},
+		{
+			name:    "connection string with sas key",
+			secrets: map[string]interface{}{"type": "azure_connection_string", "connection_string": "Endpoint=sb://events-ns/", "sas_key_name": "listen", "sas_key": "k"},
+			wantErrs: []string{
+				`azure_event_hubs secrets: sas_key_name cannot be set when type is "azure_connection_string"`,
+				`azure_event_hubs secrets: sas_key cannot be set when type is "azure_connection_string"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCheckErrors(t, testConnectionDiff("azure_event_hubs", testEventHubsBlock(tt.secrets)), tt.wantErrs)
+		})
+	}
+}
+
+func TestAzureEventHubsConnectionDefaultConsumerGroup(t *testing.T) {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"project_id": "p",
+		"name":       "c",
+		"azure_event_hubs": []interface{}{testEventHubsBlock(map[string]interface{}{
+			"type":              "azure_connection_string",
+			"connection_string": "Endpoint=sb://events-ns/",
+		})},
+	})
+	diff, err := resourcePolarisConnection().Diff(context.Background(), nil, config, nil)
+	if err != nil {
+		t.Fatalf("diff: %s", err)
+	}
+	if got := diff.Attributes["azure_event_hubs.0.consumer_group"]; got == nil || got.New != "$Default" {
+		t.Errorf("consumer_group diff = %#v, want $Default", got)
+	}
+}
+
+func TestExpandAzureEventHubsConnection(t *testing.T) {
+	connection := map[string]interface{}{}
+	expandAzureEventHubsConnection(map[string]interface{}{
+		"namespace":      "events-ns",
+		"event_hub_name": "events",
+		"consumer_group": "$Default",
+		"secrets": []interface{}{map[string]interface{}{
+			"type":              "azure_sas_key",
+			"connection_string": "",
+			"sas_key_name":      "listen",
+			"sas_key":           "k",
+		}},
+	}, connection)
+
+	want := map[string]interface{}{
+		"namespace":     "events-ns",
+		"eventHubName":  "events",
+		"consumerGroup": "$Default",
+		"secrets":       map[string]interface{}{"type": "azure_sas_key", "sasKeyName": "listen", "sasKey": "k"},
+	}
+	if !reflect.DeepEqual(connection, want) {
+		t.Errorf("connection = %#v, want %#v", connection, want)
+	}
+}
+
+func TestFlattenAzureEventHubsConnection(t *testing.T) {
+	// Polaris returns the SAS key name but not the key.
+	connection := map[string]interface{}{
+		"namespace":     "events-ns",
+		"eventHubName":  "events",
+		"consumerGroup": "readers",
+		"secrets":       map[string]interface{}{"type": "azure_sas_key", "sasKeyName": "listen"},
+	}
+	prior := map[string]interface{}{
+		"secrets": []interface{}{map[string]interface{}{
+			"type":              "azure_sas_key",
+			"connection_string": "",
+			"sas_key_name":      "old-name",
+			"sas_key":           "k",
+		}},
+	}
+
+	got := flattenAzureEventHubsConnection(connection, prior)
+	want := map[string]interface{}{
+		"namespace":      "events-ns",
+		"event_hub_name": "events",
+		"consumer_group": "readers",
+		"secrets": []interface{}{map[string]interface{}{
+			"type":              "azure_sas_key",
+			"connection_string": "",
+			"sas_key_name":      "listen",
+			"sas_key":           "k",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattened = %#v, want %#v", got, want)
+	}
+
+	// The connection string is carried over the same way.
+	connection["secrets"] = map[string]interface{}{"type": "azure_connection_string"}
+	prior["secrets"] = []interface{}{map[string]interface{}{
+		"type":              "azure_connection_string",
+		"connection_string": "Endpoint=sb://events-ns/",
+		"sas_key_name":      "",
+		"sas_key":           "",
+	}}
+	got = flattenAzureEventHubsConnection(connection, prior)
+	secrets := got["secrets"].([]interface{})[0].(map[string]interface{})
+	if secrets["connection_string"] != "Endpoint=sb://events-ns/" {
+		t.Errorf("connection_string = %#v, want it carried over from prior", secrets["connection_string"])
+	}
+}
+
+func TestConfluentSchemaRegistryConnectionURLValidation(t *testing.T) {
+	block := func(urls ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"urls": urls}
+	}
+
+	if errs := testConnectionValidate("confluent_schema_registry", block("https://registry.example.com", "http://10.0.0.1:8081")); len(errs) > 0 {
+		t.Errorf("valid urls: %q", errs)
+	}
+	errs := testConnectionValidate("confluent_schema_registry", block("https://registry.example.com", "registry.example.com", "ftp://registry.example.com"))
+	if len(errs) != 2 {
+		t.Errorf("invalid urls: got %q, want 2 errors", errs)
+	}
+}
+
+func TestConfluentSchemaRegistryConnectionRoundTrip(t *testing.T) {
+	block := map[string]interface{}{
+		"urls": []interface{}{"https://a.example.com", "https://b.example.com"},
+		"secrets": []interface{}{map[string]interface{}{
+			"type":       "basic_auth",
+			"api_key":    "key",
+			"api_secret": "secret",
+		}},
+	}
+
+	connection := map[string]interface{}{}
+	expandConfluentSchemaRegistryConnection(block, connection)
+	want := map[string]interface{}{
+		"urls":    []string{"https://a.example.com", "https://b.example.com"},
+		"secrets": map[string]interface{}{"type": "basic_auth", "apiKey": "key", "apiSecret": "secret"},
+	}
+	if !reflect.DeepEqual(connection, want) {
+		t.Fatalf("expanded = %#v, want %#v", connection, want)
+	}
+
+	// Polaris reads the connection back with the API key but not the secret,
+	// and the key may have been rotated outside Terraform.
+	connection = map[string]interface{}{
+		"urls":    []interface{}{"https://a.example.com", "https://b.example.com"},
+		"secrets": map[string]interface{}{"type": "basic_auth", "apiKey": "rotated"},
+	}
+	got := flattenConfluentSchemaRegistryConnection(connection, block)
+	wantFlat := map[string]interface{}{
+		"urls": []interface{}{"https://a.example.com", "https://b.example.com"},
+		"secrets": []interface{}{map[string]interface{}{
+			"type":       "basic_auth",
+			"api_key":    "rotated",
+			"api_secret": "secret",
+		}},
+	}
+	if !reflect.DeepEqual(got, wantFlat) {
+		t.Errorf("flattened = %#v, want %#v", got, wantFlat)
+	}
+}
@@ -1,32 +1,72 @@
 package polaris
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"log"
-	"net/http"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourcePolarisTable() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePolarisTableCreate,
-		Read:   resourcePolarisTableRead,
-		Update: resourcePolarisTableUpdate,
-		Delete: resourcePolarisTableDelete,
+		CreateContext: resourcePolarisTableCreate,
+		ReadContext:   resourcePolarisTableRead,
+		UpdateContext: resourcePolarisTableUpdate,
+		DeleteContext: resourcePolarisTableDelete,
+		CustomizeDiff: resourcePolarisTableCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePolarisTableImport,
+		},
+		Identity: &schema.ResourceIdentity{
+			SchemaFunc: resourcePolarisTableIdentitySchema,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(tableDefaultTimeout),
+			Read:   schema.DefaultTimeout(defaultReadTimeout),
+			Update: schema.DefaultTimeout(tableDefaultTimeout),
+			Delete: schema.DefaultTimeout(tableDefaultTimeout),
+		},
+		SchemaVersion: 4,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourcePolarisTableV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePolarisTableStateUpgradeV0,
+			},
+			{
+				Version: 1,
+				Type:    resourcePolarisTableV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePolarisTableStateUpgradeV1,
+			},
+			{
+				Version: 2,
+				Type:    resourcePolarisTableV2().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePolarisTableStateUpgradeV2,
+			},
+			{
+				Version: 3,
+				Type:    resourcePolarisTableV3().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePolarisTableStateUpgradeV3,
+			},
+		},
 
 		Schema: map[string]*schema.Schema{
 			"project_id": {
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
 			},
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
 			},
 			"type": {
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
 			},
 			"description": {
 				Type:     schema.TypeString,
@@ -35,7 +75,7 @@ func resourcePolarisTable() *schema.Resource {
 			"version": {
 				Type:     schema.TypeInt,
 				Optional: true,
-				Default:  0,
+				Computed: true,
 			},
 			"clustering_columns": {
 				Type:     schema.TypeList,
@@ -48,9 +88,33 @@ func resourcePolarisTable() *schema.Resource {
 				Default:  "day",
 			},
 			"query_granularity": {
-				Type:     schema.TypeMap,
+				Type:     schema.TypeList,
 				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(queryGranularityTypes, false),
+						},
+						"period": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringMatch(isoPeriodPattern, "must be an ISO-8601 period such as PT15M or P1D"),
+						},
+						"time_zone": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateTimeZone,
+						},
+						"origin": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsRFC3339Time,
+						},
+					},
+				},
 			},
 			"schema": {
 				Type:     schema.TypeList,
@@ -73,48 +137,30 @@ func resourcePolarisTable() *schema.Resource {
 							Type:     schema.TypeBool,
 							Optional: true,
 						},
-					},
-				},
-			},
-			"schema_mode": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"storage_policy": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"cached": {
+						"aggregate_function": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"sketch": {
 							Type:     schema.TypeList,
 							Optional: true,
 							MaxItems: 1,
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
-									"type": {
-										Type:     schema.TypeString,
+									"lg_k": {
+										Type:     schema.TypeInt,
 										Optional: true,
 									},
-									"intervals": {
+									"tgt_hll_type": {
 										Type:     schema.TypeString,
 										Optional: true,
 									},
-								},
-							},
-						},
-						"retain": {
-							Type:     schema.TypeList,
-							Optional: true,
-							MaxItems: 1,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"type": {
-										Type:     schema.TypeString,
+									"size": {
+										Type:     schema.TypeInt,
 										Optional: true,
 									},
-									"intervals": {
-										Type:     schema.TypeString,
+									"k": {
+										Type:     schema.TypeInt,
 										Optional: true,
 									},
 								},
@@ -123,12 +169,32 @@ func resourcePolarisTable() *schema.Resource {
 					},
 				},
 			},
-
+			"schema_mode": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"storage_policy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cached": storagePolicyRuleSchema(),
+						"retain": storagePolicyRuleSchema(),
+					},
+				},
+			},
 			"time_resolution": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Default:  "millisecond",
 			},
+			"wait_for_availability": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
 			"availability": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -145,7 +211,7 @@ func resourcePolarisTable() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
-			"id": {
+			"table_id": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
@@ -200,32 +266,52 @@ func resourcePolarisTable() *schema.Resource {
 	}
 }
 
-func resourcePolarisTableCreate(d *schema.ResourceData, m interface{}) error {
+// storagePolicyRuleSchema is the schema of the cached and retain rules of a
+// storage policy. A rule that is not configured is left as it is in Polaris,
+// while removing a rule from the configuration resets it to
+// defaultStoragePolicyDetail.
+func storagePolicyRuleSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(storagePolicyTypes, false),
+				},
+				"period": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringMatch(isoPeriodPattern, "must be an ISO-8601 period such as P30D or P1Y"),
+				},
+				"intervals": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validateISOInterval,
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourcePolarisTableCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*Client)
 	projectID := d.Get("project_id").(string)
 	tableName := d.Get("name").(string)
 
 	// Check if the table already exists
-	exists, err := tableExists(client, projectID, tableName)
+	exists, err := tableExists(ctx, client, projectID, tableName)
 	if err != nil {
-		return fmt.Errorf("Error checking if table exists: %s", err)
+		return diag.Errorf("Error checking if table exists: %s", err)
 	}
 	if exists {
-		return fmt.Errorf("Table already exists")
-	}
-
-	var queryGranularity map[string]interface{}
-	if v, ok := d.GetOk("query_granularity"); ok {
-		queryGranularity = v.(map[string]interface{})
-	} else {
-		queryGranularity = nil
-	}
-
-	var storagePolicy []interface{}
-	if v, ok := d.GetOk("storage_policy"); ok {
-		storagePolicy = v.([]interface{})
-	} else {
-		storagePolicy = nil
+		return diag.Errorf("Table %s already exists in project %s", tableName, projectID)
 	}
 
 	table := Table{
@@ -235,154 +321,210 @@ func resourcePolarisTableCreate(d *schema.ResourceData, m interface{}) error {
 		Description:             getStringPointer(d, "description"),
 		ClusteringColumns:       getStringListPointer(d, "clustering_columns"),
 		PartitioningGranularity: d.Get("partitioning_granularity").(string),
-		QueryGranularity:        expandQueryGranularity(queryGranularity),
+		QueryGranularity:        expandQueryGranularity(d.Get("query_granularity").([]interface{})),
 		Schema:                  expandSchema(d.Get("schema").([]interface{})),
 		SchemaMode:              d.Get("schema_mode").(string),
-		StoragePolicy:           expandStoragePolicy(storagePolicy),
+		StoragePolicy:           expandStoragePolicy(d.Get("storage_policy").([]interface{})),
 		TimeResolution:          d.Get("time_resolution").(string),
 		Availability:            d.Get("availability").(string),
 	}
-	log.Printf("[DEBUG] Created table with ID&&&&&&&&&&&&&&&&&&&&&&&: %s", table.ID)
 
-	err = client.CreateTable(projectID, &table)
+	created, err := client.CreateTable(ctx, projectID, &table)
+	if IsConflict(err) {
+		return diag.Errorf("Table %s already exists in project %s: %s", tableName, projectID, err)
+	}
 	if err != nil {
-		return fmt.Errorf("Error creating table: %s", err)
+		return apiErrorDiagnostics("Error creating table", err, resourcePolarisTable().Schema)
+	}
+	if created.ID == "" {
+		return diag.Errorf("Error creating table: table ID not set in response")
 	}
 
-	log.Printf("[DEBUG] Created table with ID&&&&&&&&&&&&&&&&&&&&&&&&: %s", table.ID)
-	d.SetId(table.ID)                     // Ensure the resource ID is set
-	return resourcePolarisTableRead(d, m) // Read the resource state to ensure consistency
-}
+	tflog.Debug(ctx, "Created table", map[string]interface{}{
+		"project_id": projectID,
+		"name":       tableName,
+		"table_id":   created.ID,
+	})
+	d.SetId(compositeID(projectID, created.Name))
 
-func convertSchemaColumnsToSchema(columns []SchemaColumn) []Schema {
-	schema := make([]Schema, len(columns))
-	for i, col := range columns {
-		schema[i] = Schema{
-			Type:     col.Type,
-			DataType: col.DataType,
-			Name:     col.Name,
+	if d.Get("wait_for_availability").(bool) {
+		if _, err := waitForTableAvailability(ctx, client, projectID, created.Name, d.Get("availability").(string), d.Timeout(schema.TimeoutCreate)); err != nil {
+			return diag.Errorf("Error creating table: %s", err)
 		}
 	}
-	return schema
+	return resourcePolarisTableRead(ctx, d, m) // Read the resource state to ensure consistency
 }
 
-func convertSchemaColumnsToQueryableSchema(columns []SchemaColumn) []QueryableSchema {
-	queryableSchema := make([]QueryableSchema, len(columns))
-	for i, col := range columns {
-		queryableSchema[i] = QueryableSchema{
-			Name: col.Name,
-		}
+// resourcePolarisTableIdentitySchema is the resource identity of
+// polaris_table: a table is identified by its project and name.
+func resourcePolarisTableIdentitySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"project_id": {
+			Type:              schema.TypeString,
+			RequiredForImport: true,
+		},
+		"name": {
+			Type:              schema.TypeString,
+			RequiredForImport: true,
+		},
 	}
-	return queryableSchema
 }
 
-func resourcePolarisTableRead(d *schema.ResourceData, m interface{}) error {
+func resourcePolarisTableRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*Client)
-	projectID := d.Get("project_id").(string)
-	tableName := d.Get("name").(string) // Use table name instead of table ID
-
-	url := fmt.Sprintf("/v1/projects/%s/tables/%s", projectID, tableName)
-	response, err := client.Get(url)
+	projectID, tableName, err := parseCompositeID(d.Id(), "project_id/table_name")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusNotFound {
-		log.Print("[DEBUG]Not Found ^^^^^^^^^^^^^^^^^^^: %s", http.StatusNotFound)
+	table, err := client.GetTable(ctx, projectID, tableName)
+	if IsNotFound(err) {
+		tflog.Warn(ctx, "Table not found, removing from state", map[string]interface{}{
+			"project_id": projectID,
+			"name":       tableName,
+		})
 		d.SetId("")
 		return nil
 	}
-
-	if response.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("Unauthorized: Please check your API key and permissions")
+	if IsUnauthorized(err) {
+		return diag.Errorf("Unauthorized: Please check your API key and permissions: %s", err)
 	}
-
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("Unexpected status code: %d", response.StatusCode)
+	if err != nil {
+		return diag.Errorf("Error reading table: %s", err)
 	}
 
-	var table Table
-	if err := json.NewDecoder(response.Body).Decode(&table); err != nil {
-		return err
+	if err := d.Set("project_id", projectID); err != nil {
+		return diag.FromErr(err)
+	}
+	// type is required, so it is only missing from state when the table is
+	// being imported.
+	imported := d.Get("type").(string) == ""
+	priorStoragePolicy := d.Get("storage_policy").([]interface{})
+	if err := setTableState(d, table); err != nil {
+		return diag.Errorf("Error reading table: %s", err)
+	}
+	if err := d.Set("storage_policy", flattenConfiguredStoragePolicy(table.StoragePolicy, priorStoragePolicy, imported)); err != nil {
+		return diag.Errorf("Error reading table: %s", err)
+	}
+	if err := setTableIdentity(d, projectID, table.Name); err != nil {
+		return diag.Errorf("Error reading table: %s", err)
 	}
 
-	log.Printf("[DEBUG] Read table: %+v", table)
-
-	d.Set("name", table.Name)
-	d.Set("type", table.Type)
-	d.Set("description", table.Description)
-	d.Set("version", table.Version)
-	d.Set("clustering_columns", table.ClusteringColumns)
-	d.Set("partitioning_granularity", table.PartitioningGranularity)
-	d.Set("query_granularity", flattenQueryGranularity(table.QueryGranularity))
-	d.Set("schema", flattenSchema(convertSchemaColumnsToSchema(table.Schema)))
-	d.Set("schema_mode", table.SchemaMode)
-	d.Set("storage_policy", flattenStoragePolicy(table.StoragePolicy))
-	d.Set("time_resolution", table.TimeResolution)
-	d.Set("availability", table.Availability)
-	d.Set("created_by_user", flattenUser(table.CreatedByUser))
-	d.Set("created_on_timestamp", table.CreatedOnTimestamp)
-	d.Set("id", table.ID)
-	d.Set("modified_by_user", flattenUser(table.ModifiedByUser))
-	d.Set("modified_on_timestamp", table.ModifiedOnTimestamp)
-	d.Set("segment_compacted_bytes", table.SegmentCompactedBytes)
-	d.Set("segment_total_bytes", table.SegmentTotalBytes)
-	d.Set("total_data_size_bytes", table.TotalDataSizeBytes)
-	d.Set("total_rows", table.TotalRows)
-	d.Set("queryable_schema", flattenQueryableSchema(convertSchemaColumnsToQueryableSchema(table.QueryableSchema)))
-
-	log.Printf("[DEBUG] Finished reading table with ID: %s, State: %+v", tableName, d.State())
+	tflog.Debug(ctx, "Read table", map[string]interface{}{
+		"project_id": projectID,
+		"name":       tableName,
+		"table_id":   table.ID,
+	})
 
 	return nil
 }
 
-func flattenQueryGranularity(qg *QueryGranularity) map[string]interface{} {
-	if qg == nil {
+func flattenQueryGranularity(qg *QueryGranularity) []interface{} {
+	if qg == nil || qg.Type == "" {
 		return nil
 	}
 
-	return map[string]interface{}{
-		"type": qg.Type,
+	return []interface{}{
+		map[string]interface{}{
+			"type":      qg.Type,
+			"period":    qg.Period,
+			"time_zone": qg.TimeZone,
+			"origin":    qg.Origin,
+		},
 	}
 }
 
-type Schema struct {
-	Type     string `json:"type"`
-	DataType string `json:"dataType"`
-	Name     string `json:"name"`
-}
-
-func flattenSchema(schema []Schema) []map[string]interface{} {
-	if schema == nil {
+func flattenSchema(columns []SchemaColumn) []interface{} {
+	if columns == nil {
 		return nil
 	}
 
-	flatSchema := make([]map[string]interface{}, len(schema))
-	for i, s := range schema {
+	flatSchema := make([]interface{}, len(columns))
+	for i, column := range columns {
 		flatSchema[i] = map[string]interface{}{
-			"type":     s.Type,
-			"dataType": s.DataType,
-			"name":     s.Name,
+			"name":               column.Name,
+			"type":               column.Type,
+			"data_type":          column.DataType,
+			"primary_key":        column.PrimaryKey,
+			"aggregate_function": column.AggregateFunction,
+			"sketch":             flattenSketch(column),
 		}
 	}
 
 	return flatSchema
 }
 
-func flattenStoragePolicy(sp *StoragePolicy) []map[string]interface{} {
+func flattenSketch(column SchemaColumn) []interface{} {
+	if column.LgK == 0 && column.TgtHllType == "" && column.Size == 0 && column.K == 0 {
+		return nil
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"lg_k":         column.LgK,
+			"tgt_hll_type": column.TgtHllType,
+			"size":         column.Size,
+			"k":            column.K,
+		},
+	}
+}
+
+func flattenStoragePolicy(sp *StoragePolicy) []interface{} {
+	if sp == nil || (sp.Cached == nil && sp.Retain == nil) {
+		return nil
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"cached": flattenStoragePolicyDetail(sp.Cached),
+			"retain": flattenStoragePolicyDetail(sp.Retain),
+		},
+	}
+}
+
+// flattenConfiguredStoragePolicy is flattenStoragePolicy for the resource.
+// Only the rules declared by prior, the storage policy currently in state,
+// are kept: the others are managed outside Terraform and must not show up as
+// a difference. An imported table has no prior state, so it keeps every rule
+// that differs from defaultStoragePolicyDetail instead.
+func flattenConfiguredStoragePolicy(sp *StoragePolicy, prior []interface{}, imported bool) []interface{} {
 	if sp == nil {
 		return nil
 	}
 
-	return []map[string]interface{}{
-		{
-			"cached": map[string]interface{}{
-				"type": sp.Cached.Type,
-			},
-			"retain": map[string]interface{}{
-				"type": sp.Retain.Type,
-			},
+	declared := expandStoragePolicy(prior)
+	if declared == nil {
+		declared = &StoragePolicy{}
+	}
+	configured := &StoragePolicy{Cached: sp.Cached, Retain: sp.Retain}
+	if imported {
+		if isDefaultStoragePolicyDetail(configured.Cached) {
+			configured.Cached = nil
+		}
+		if isDefaultStoragePolicyDetail(configured.Retain) {
+			configured.Retain = nil
+		}
+	} else {
+		if declared.Cached == nil {
+			configured.Cached = nil
+		}
+		if declared.Retain == nil {
+			configured.Retain = nil
+		}
+	}
+	return flattenStoragePolicy(configured)
+}
+
+func flattenStoragePolicyDetail(spd *StoragePolicyDetail) []interface{} {
+	if spd == nil {
+		return nil
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"type":      spd.Type,
+			"period":    spd.Period,
+			"intervals": spd.Intervals,
 		},
 	}
 }
@@ -398,38 +540,118 @@ func flattenUser(user *User) map[string]interface{} {
 	}
 }
 
-// Define the QueryableSchema type
-type QueryableSchema struct {
-	Name string `json:"name"`
-}
-
-func flattenQueryableSchema(qs []QueryableSchema) []map[string]interface{} {
-	if qs == nil {
+func flattenQueryableSchema(columns []SchemaColumn) []interface{} {
+	if columns == nil {
 		return nil
 	}
 
-	flatQueryableSchema := make([]map[string]interface{}, len(qs))
-	for i, q := range qs {
+	flatQueryableSchema := make([]interface{}, len(columns))
+	for i, column := range columns {
 		flatQueryableSchema[i] = map[string]interface{}{
-			"name": q.Name,
+			"name":      column.Name,
+			"type":      column.Type,
+			"data_type": column.DataType,
 		}
 	}
 
 	return flatQueryableSchema
 }
 
-func resourcePolarisTableUpdate(d *schema.ResourceData, m interface{}) error {
-	client := m.(*Client)
-	projectID := d.Get("project_id").(string)
-	tableID := d.Id()
+// setTableState copies every attribute of table into d.
+func setTableState(d *schema.ResourceData, table *Table) error {
+	var description string
+	if table.Description != nil {
+		description = *table.Description
+	}
+	var clusteringColumns []string
+	if table.ClusteringColumns != nil {
+		clusteringColumns = *table.ClusteringColumns
+	}
+
+	values := map[string]interface{}{
+		"name":                     table.Name,
+		"type":                     table.Type,
+		"description":              description,
+		"version":                  table.Version,
+		"clustering_columns":       clusteringColumns,
+		"partitioning_granularity": table.PartitioningGranularity,
+		"query_granularity":        flattenQueryGranularity(table.QueryGranularity),
+		"schema":                   flattenSchema(table.Schema),
+		"schema_mode":              table.SchemaMode,
+		"storage_policy":           flattenStoragePolicy(table.StoragePolicy),
+		"time_resolution":          table.TimeResolution,
+		"availability":             table.Availability,
+		"created_by_user":          flattenUser(table.CreatedByUser),
+		"created_on_timestamp":     table.CreatedOnTimestamp,
+		"table_id":                 table.ID,
+		"modified_by_user":         flattenUser(table.ModifiedByUser),
+		"modified_on_timestamp":    table.ModifiedOnTimestamp,
+		"segment_compacted_bytes":  table.SegmentCompactedBytes,
+		"segment_total_bytes":      table.SegmentTotalBytes,
+		"total_data_size_bytes":    table.TotalDataSizeBytes,
+		"total_rows":               table.TotalRows,
+		"queryable_schema":         flattenQueryableSchema(table.QueryableSchema),
+	}
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return fmt.Errorf("error setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
 
-	var storagePolicy []interface{}
-	if v, ok := d.GetOk("storage_policy"); ok {
-		storagePolicy = v.([]interface{})
+// setTableIdentity records the resource identity of the table in d.
+func setTableIdentity(d *schema.ResourceData, projectID, name string) error {
+	identity, err := d.Identity()
+	if err != nil {
+		return err
+	}
+	if err := identity.Set("project_id", projectID); err != nil {
+		return err
+	}
+	return identity.Set("name", name)
+}
+
+// resourcePolarisTableImport imports a table either by a "project_id/table_name"
+// ID or, from an import block, by its resource identity.
+func resourcePolarisTableImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	var projectID, name string
+	if d.Id() != "" {
+		var err error
+		projectID, name, err = parseCompositeID(d.Id(), "project_id/table_name")
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		storagePolicy = nil
+		identity, err := d.Identity()
+		if err != nil {
+			return nil, err
+		}
+		projectID = identity.Get("project_id").(string)
+		name = identity.Get("name").(string)
+	}
+
+	if err := d.Set("project_id", projectID); err != nil {
+		return nil, err
+	}
+	if err := d.Set("name", name); err != nil {
+		return nil, err
+	}
+	if err := d.Set("wait_for_availability", true); err != nil {
+		return nil, err
 	}
+	d.SetId(compositeID(projectID, name))
+	return []*schema.ResourceData{d}, nil
+}
 
+func resourcePolarisTableUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	projectID, tableName, err := parseCompositeID(d.Id(), "project_id/table_name")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	oldStoragePolicy, newStoragePolicy := d.GetChange("storage_policy")
 	table := Table{
 		Name:                    d.Get("name").(string),
 		Type:                    d.Get("type").(string),
@@ -437,76 +659,67 @@ func resourcePolarisTableUpdate(d *schema.ResourceData, m interface{}) error {
 		Version:                 d.Get("version").(int),
 		ClusteringColumns:       getStringListPointer(d, "clustering_columns"),
 		PartitioningGranularity: d.Get("partitioning_granularity").(string),
-		QueryGranularity:        expandQueryGranularity(d.Get("query_granularity").(map[string]interface{})),
+		QueryGranularity:        expandQueryGranularity(d.Get("query_granularity").([]interface{})),
 		Schema:                  expandSchema(d.Get("schema").([]interface{})),
 		SchemaMode:              d.Get("schema_mode").(string),
-		StoragePolicy:           expandStoragePolicy(storagePolicy),
+		StoragePolicy:           expandStoragePolicyUpdate(oldStoragePolicy.([]interface{}), newStoragePolicy.([]interface{})),
 		TimeResolution:          d.Get("time_resolution").(string),
 		Availability:            d.Get("availability").(string),
 	}
 
-	url := fmt.Sprintf("/v1/projects/%s/tables/%s", projectID, tableID)
-	response, err := client.Put(url, table)
-	if err != nil {
-		return fmt.Errorf("Error updating table: %s", err)
+	if _, err := client.UpdateTable(ctx, projectID, tableName, &table); err != nil {
+		return apiErrorDiagnostics("Error updating table", err, resourcePolarisTable().Schema)
 	}
-	defer response.Body.Close()
 
-	log.Printf("[DEBUG] Updated table with ID: %s", tableID)
-	return resourcePolarisTableRead(d, m) // Read the resource state to ensure consistency
+	tflog.Debug(ctx, "Updated table", map[string]interface{}{
+		"project_id": projectID,
+		"name":       tableName,
+	})
+
+	if d.Get("wait_for_availability").(bool) {
+		if _, err := waitForTableAvailability(ctx, client, projectID, tableName, d.Get("availability").(string), d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.Errorf("Error updating table: %s", err)
+		}
+	}
+	return resourcePolarisTableRead(ctx, d, m) // Read the resource state to ensure consistency
 }
 
-func resourcePolarisTableDelete(d *schema.ResourceData, m interface{}) error {
+func resourcePolarisTableDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*Client)
-	projectID := d.Get("project_id").(string)
-	tableID := d.Id()
-
-	url := fmt.Sprintf("/v1/projects/%s/tables/%s", projectID, tableID)
-	req, err := http.NewRequest("DELETE", client.baseURL+url, nil)
+	projectID, tableName, err := parseCompositeID(d.Id(), "project_id/table_name")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+client.apiKey)
 
-	response, err := client.httpClient.Do(req)
+	err = client.DeleteTable(ctx, projectID, tableName)
+	if IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
-		return err
+		return diag.Errorf("Error deleting table: %s", err)
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("Unexpected status code: %d", response.StatusCode)
+	if d.Get("wait_for_availability").(bool) {
+		if err := waitForTableDeletion(ctx, client, projectID, tableName, d.Timeout(schema.TimeoutDelete)); err != nil {
+			return diag.Errorf("Error deleting table: %s", err)
+		}
 	}
 
 	d.SetId("")
 	return nil
 }
 
-func tableExists(client *Client, projectID, tableName string) (bool, error) {
-	url := fmt.Sprintf("/v1/projects/%s/tables", projectID)
-	response, err := client.Get(url)
-	if err != nil {
-		return false, err
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode == http.StatusUnauthorized {
-		return false, fmt.Errorf("Unauthorized: Please check your API key and permissions")
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("Unexpected status code: %d", response.StatusCode)
-	}
-
-	var tablesResponse struct {
-		Values []Table `json:"values"`
+func tableExists(ctx context.Context, client *Client, projectID, tableName string) (bool, error) {
+	tables, err := client.ListTables(ctx, projectID)
+	if IsUnauthorized(err) {
+		return false, fmt.Errorf("Unauthorized: Please check your API key and permissions: %w", err)
 	}
-	if err := json.NewDecoder(response.Body).Decode(&tablesResponse); err != nil {
+	if err != nil {
 		return false, err
 	}
 
-	for _, table := range tablesResponse.Values {
+	for _, table := range tables {
 		if table.Name == tableName {
 			return true, nil
 		}
@@ -533,15 +746,18 @@ func getStringListPointer(d *schema.ResourceData, key string) *[]string {
 	return nil
 }
 
-func expandQueryGranularity(data map[string]interface{}) *QueryGranularity {
-	if data == nil {
+func expandQueryGranularity(data []interface{}) *QueryGranularity {
+	if len(data) == 0 || data[0] == nil {
 		return nil
 	}
-	qg := &QueryGranularity{}
-	if v, ok := data["type"].(string); ok {
-		qg.Type = v
+
+	qgMap := data[0].(map[string]interface{})
+	return &QueryGranularity{
+		Type:     qgMap["type"].(string),
+		Period:   qgMap["period"].(string),
+		TimeZone: qgMap["time_zone"].(string),
+		Origin:   qgMap["origin"].(string),
 	}
-	return qg
 }
 
 func expandSchema(data []interface{}) []SchemaColumn {
@@ -554,11 +770,29 @@ func expandSchema(data []interface{}) []SchemaColumn {
 			DataType:   fieldData["data_type"].(string),
 			PrimaryKey: fieldData["primary_key"].(bool),
 		}
+		if v, ok := fieldData["aggregate_function"].(string); ok {
+			field.AggregateFunction = v
+		}
+		if sketch, ok := fieldData["sketch"].([]interface{}); ok {
+			expandSketch(sketch, &field)
+		}
 		schema = append(schema, field)
 	}
 	return schema
 }
 
+func expandSketch(data []interface{}, column *SchemaColumn) {
+	if len(data) == 0 || data[0] == nil {
+		return
+	}
+
+	sketch := data[0].(map[string]interface{})
+	column.LgK = sketch["lg_k"].(int)
+	column.TgtHllType = sketch["tgt_hll_type"].(string)
+	column.Size = sketch["size"].(int)
+	column.K = sketch["k"].(int)
+}
+
 func expandStoragePolicy(data []interface{}) *StoragePolicy {
 	if len(data) == 0 || data[0] == nil {
 		return nil
@@ -577,6 +811,37 @@ func expandStoragePolicy(data []interface{}) *StoragePolicy {
 	return sp
 }
 
+// expandStoragePolicyUpdate builds the storage policy sent when a table's
+// configuration changes from old to new. Only configured rules are sent, so
+// rules managed outside Terraform are left alone, except that a rule removed
+// from the configuration is sent as defaultStoragePolicyDetail to reset it.
+func expandStoragePolicyUpdate(old, new []interface{}) *StoragePolicy {
+	sp := expandStoragePolicy(new)
+	if sp == nil {
+		sp = &StoragePolicy{}
+	}
+	prior := expandStoragePolicy(old)
+	if prior == nil {
+		prior = &StoragePolicy{}
+	}
+	if sp.Cached == nil && prior.Cached != nil {
+		cached := defaultStoragePolicyDetail
+		sp.Cached = &cached
+	}
+	if sp.Retain == nil && prior.Retain != nil {
+		retain := defaultStoragePolicyDetail
+		sp.Retain = &retain
+	}
+	if sp.Cached == nil && sp.Retain == nil {
+		return nil
+	}
+	return sp
+}
+
+func isDefaultStoragePolicyDetail(spd *StoragePolicyDetail) bool {
+	return spd != nil && spd.Type == defaultStoragePolicyDetail.Type && spd.Period == "" && len(spd.Intervals) == 0
+}
+
 func expandStoragePolicyDetail(data map[string]interface{}) *StoragePolicyDetail {
 	if data == nil {
 		return nil
@@ -585,6 +850,9 @@ func expandStoragePolicyDetail(data map[string]interface{}) *StoragePolicyDetail
 	if v, ok := data["type"].(string); ok {
 		spd.Type = v
 	}
+	if v, ok := data["period"].(string); ok {
+		spd.Period = v
+	}
 	if v, ok := data["intervals"].([]interface{}); ok {
 		var intervals []string
 		for _, interval := range v {
@@ -0,0 +1,422 @@
+package polaris
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourcePolarisTableV0 is the polaris_table schema before version 1, when
+// the resource ID was the table's server UUID and the UUID was also stored in
+// the "id" attribute. It is only used to decode old states.
+func resourcePolarisTableV0() *schema.Resource {
+	return &schema.Resource{
+
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"clustering_columns": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"partitioning_granularity": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "day",
+			},
+			"query_granularity": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"schema": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"data_type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"primary_key": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"schema_mode": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"storage_policy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cached": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"intervals": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"retain": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"intervals": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+
+			"time_resolution": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "millisecond",
+			},
+			"availability": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "available",
+			},
+			"created_by_user": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"created_on_timestamp": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"modified_by_user": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"modified_on_timestamp": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"segment_compacted_bytes": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"segment_total_bytes": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"total_data_size_bytes": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"total_rows": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"queryable_schema": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"data_type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resourcePolarisTableV1 is the polaris_table schema before version 2, when
+// query_granularity was a map of strings. It is derived from version 0 with
+// the changes made in version 1.
+func resourcePolarisTableV1() *schema.Resource {
+	r := resourcePolarisTableV0()
+	delete(r.Schema, "id")
+	r.Schema["table_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	columns := r.Schema["schema"].Elem.(*schema.Resource).Schema
+	columns["aggregate_function"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+	columns["sketch"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"lg_k": {
+					Type:     schema.TypeInt,
+					Optional: true,
+				},
+				"tgt_hll_type": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"size": {
+					Type:     schema.TypeInt,
+					Optional: true,
+				},
+				"k": {
+					Type:     schema.TypeInt,
+					Optional: true,
+				},
+			},
+		},
+	}
+	return r
+}
+
+// resourcePolarisTableV2 is the polaris_table schema before version 3, when
+// storage policy intervals were a single string. It is derived from version 1
+// with the changes made in version 2.
+func resourcePolarisTableV2() *schema.Resource {
+	r := resourcePolarisTableV1()
+	r.Schema["query_granularity"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"period": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"time_zone": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"origin": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+		},
+	}
+	return r
+}
+
+// resourcePolarisTableV3 is the polaris_table schema before version 4, which
+// added wait_for_availability. It is derived from version 2 with the changes
+// made in version 3.
+func resourcePolarisTableV3() *schema.Resource {
+	r := resourcePolarisTableV2()
+	rule := &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"period": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"intervals": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+	r.Schema["storage_policy"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cached": rule,
+				"retain": rule,
+			},
+		},
+	}
+	return r
+}
+
+// resourcePolarisTableStateUpgradeV0 moves the server UUID from the resource ID
+// to table_id and replaces the ID with "project_id/name".
+func resourcePolarisTableStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	projectID, _ := rawState["project_id"].(string)
+	name, _ := rawState["name"].(string)
+	if projectID == "" || name == "" {
+		return nil, fmt.Errorf("cannot upgrade polaris_table state without project_id and name")
+	}
+
+	rawState["table_id"] = rawState["id"]
+	rawState["id"] = compositeID(projectID, name)
+	return rawState, nil
+}
+
+// resourcePolarisTableStateUpgradeV1 converts the query_granularity map into
+// the query_granularity block. Keys the block does not know are dropped.
+func resourcePolarisTableStateUpgradeV1(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	old, _ := rawState["query_granularity"].(map[string]interface{})
+	granularityType, _ := old["type"].(string)
+	if granularityType == "" {
+		rawState["query_granularity"] = []interface{}{}
+		return rawState, nil
+	}
+
+	block := map[string]interface{}{
+		"type":      granularityType,
+		"period":    "",
+		"time_zone": "",
+		"origin":    "",
+	}
+	for key, aliases := range map[string][]string{
+		"period":    {"period"},
+		"time_zone": {"time_zone", "timeZone"},
+		"origin":    {"origin"},
+	} {
+		for _, alias := range aliases {
+			if v, ok := old[alias].(string); ok && v != "" {
+				block[key] = v
+			}
+		}
+	}
+	rawState["query_granularity"] = []interface{}{block}
+	return rawState, nil
+}
+
+// resourcePolarisTableStateUpgradeV2 turns the intervals string of each
+// storage policy rule into a list, splitting it on commas, and adds the empty
+// period attribute.
+func resourcePolarisTableStateUpgradeV2(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	policies, _ := rawState["storage_policy"].([]interface{})
+	for _, policy := range policies {
+		policy, ok := policy.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"cached", "retain"} {
+			rules, _ := policy[key].([]interface{})
+			for _, rule := range rules {
+				rule, ok := rule.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				intervals := []interface{}{}
+				if old, _ := rule["intervals"].(string); old != "" {
+					for _, interval := range strings.Split(old, ",") {
+						intervals = append(intervals, strings.TrimSpace(interval))
+					}
+				}
+				rule["intervals"] = intervals
+				rule["period"] = ""
+			}
+		}
+	}
+	return rawState, nil
+}
+
+// resourcePolarisTableStateUpgradeV3 sets wait_for_availability to its
+// default so existing tables do not show a change for it.
+func resourcePolarisTableStateUpgradeV3(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	if _, ok := rawState["wait_for_availability"].(bool); !ok {
+		rawState["wait_for_availability"] = true
+	}
+	return rawState, nil
+}
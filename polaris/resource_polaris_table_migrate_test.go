@@ -0,0 +1,144 @@
+package polaris
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestResourcePolarisTableStateUpgradeV0(t *testing.T) {
+	state, err := resourcePolarisTableStateUpgradeV0(context.Background(), map[string]interface{}{
+		"id":         "4d2f0e2a-5c1b-4b8e-9a57-0f6f3f1c2b10",
+		"project_id": "p",
+		"name":       "events",
+	}, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	want := map[string]interface{}{
+		"id":         "p/events",
+		"table_id":   "4d2f0e2a-5c1b-4b8e-9a57-0f6f3f1c2b10",
+		"project_id": "p",
+		"name":       "events",
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("upgraded state = %#v, want %#v", state, want)
+	}
+
+	if _, err := resourcePolarisTableStateUpgradeV0(context.Background(), map[string]interface{}{"id": "x", "name": "events"}, nil); err == nil {
+		t.Error("upgrade succeeded without project_id")
+	}
+}
+
+func TestResourcePolarisTableStateUpgradeV1(t *testing.T) {
+	tests := []struct {
+		name string
+		old  interface{}
+		want []interface{}
+	}{
+		{
+			name: "unset",
+			old:  nil,
+			want: []interface{}{},
+		},
+		{
+			name: "empty type",
+			old:  map[string]interface{}{"period": "PT1H"},
+			want: []interface{}{},
+		},
+		{
+			name: "period",
+			old:  map[string]interface{}{"type": "period", "period": "PT1H", "timeZone": "Europe/Berlin", "unknown": "x"},
+			want: []interface{}{map[string]interface{}{"type": "period", "period": "PT1H", "time_zone": "Europe/Berlin", "origin": ""}},
+		},
+		{
+			name: "time_zone key",
+			old:  map[string]interface{}{"type": "period", "period": "P1D", "time_zone": "UTC", "origin": "2024-01-01T00:00:00Z"},
+			want: []interface{}{map[string]interface{}{"type": "period", "period": "P1D", "time_zone": "UTC", "origin": "2024-01-01T00:00:00Z"}},
+		},
+		{
+			name: "all",
+			old:  map[string]interface{}{"type": "all"},
+			want: []interface{}{map[string]interface{}{"type": "all", "period": "", "time_zone": "", "origin": ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := resourcePolarisTableStateUpgradeV1(context.Background(), map[string]interface{}{
+				"id":                "p/events",
+				"query_granularity": tt.old,
+			}, nil)
+			if err != nil {
+				t.Fatalf("upgrade: %s", err)
+			}
+			if got := state["query_granularity"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query_granularity = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourcePolarisTableStateUpgradeV2(t *testing.T) {
+	state, err := resourcePolarisTableStateUpgradeV2(context.Background(), map[string]interface{}{
+		"id": "p/events",
+		"storage_policy": []interface{}{map[string]interface{}{
+			"cached": []interface{}{map[string]interface{}{
+				"intervals": "2024-01-01/2024-02-01, 2024-03-01/2024-04-01",
+			}},
+			"retain": []interface{}{map[string]interface{}{
+				"intervals": "",
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	want := []interface{}{map[string]interface{}{
+		"cached": []interface{}{map[string]interface{}{
+			"intervals": []interface{}{"2024-01-01/2024-02-01", "2024-03-01/2024-04-01"},
+			"period":    "",
+		}},
+		"retain": []interface{}{map[string]interface{}{
+			"intervals": []interface{}{},
+			"period":    "",
+		}},
+	}}
+	if got := state["storage_policy"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("storage_policy = %#v, want %#v", got, want)
+	}
+
+	state, err = resourcePolarisTableStateUpgradeV2(context.Background(), map[string]interface{}{"id": "p/events"}, nil)
+	if err != nil {
+		t.Fatalf("upgrade without storage_policy: %s", err)
+	}
+	if _, ok := state["storage_policy"]; ok {
+		t.Errorf("upgrade added storage_policy = %#v", state["storage_policy"])
+	}
+}
+
+func TestResourcePolarisTableStateUpgradeV3(t *testing.T) {
+	state, err := resourcePolarisTableStateUpgradeV3(context.Background(), map[string]interface{}{"id": "p/events"}, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	if got := state["wait_for_availability"]; got != true {
+		t.Errorf("wait_for_availability = %#v, want true", got)
+	}
+
+	state, err = resourcePolarisTableStateUpgradeV3(context.Background(), map[string]interface{}{"id": "p/events", "wait_for_availability": false}, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	if got := state["wait_for_availability"]; got != false {
+		t.Errorf("wait_for_availability = %#v, want the existing false", got)
+	}
+}
+
+func TestResourcePolarisTableStateUpgradeNil(t *testing.T) {
+	for i, upgrade := range resourcePolarisTable().StateUpgraders {
+		state, err := upgrade.Upgrade(context.Background(), nil, nil)
+		if state != nil || err != nil {
+			t.Errorf("upgrader V%d(nil) = %#v, %v, want nil, nil", i, state, err)
+		}
+	}
+}
@@ -0,0 +1,536 @@
+package polaris
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestResourcePolarisTable(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	config := func(description string) string {
+		return testProviderConfig(srv) + fmt.Sprintf(`
+resource "polaris_table" "events" {
+  project_id  = "p"
+  name        = "events"
+  type        = "detail"
+  description = %q
+
+  query_granularity {
+    type      = "period"
+    period    = "PT1H"
+    time_zone = "Europe/Berlin"
+  }
+
+  schema {
+    name      = "country"
+    type      = "dimension"
+    data_type = "string"
+  }
+}
+`, description)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		CheckDestroy:      testCheckPolarisTableDestroyed(srv, "p", "events"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("first"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_table.events", "id", "p/events"),
+					resource.TestCheckResourceAttr("polaris_table.events", "description", "first"),
+					resource.TestCheckResourceAttr("polaris_table.events", "availability", "available"),
+					resource.TestCheckResourceAttr("polaris_table.events", "query_granularity.0.time_zone", "Europe/Berlin"),
+					resource.TestCheckResourceAttrSet("polaris_table.events", "table_id"),
+					testCheckPolarisTable(srv, "p", "events", "description", "first"),
+				),
+			},
+			{
+				Config: config("second"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_table.events", "description", "second"),
+					testCheckPolarisTable(srv, "p", "events", "description", "second"),
+				),
+			},
+			{
+				ResourceName:      "polaris_table.events",
+				ImportState:       true,
+				ImportStateId:     "p/events",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourcePolarisTableImport(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutTable("p", map[string]interface{}{
+		"name":        "existing",
+		"type":        "detail",
+		"description": "created elsewhere",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "polaris_table" "existing" {
+  project_id  = "p"
+  name        = "existing"
+  type        = "detail"
+  description = "created elsewhere"
+}
+`,
+				ResourceName:       "polaris_table.existing",
+				ImportState:        true,
+				ImportStateId:      "p/existing",
+				ImportStatePersist: true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("imported %d resources, want 1", len(states))
+					}
+					attrs := states[0].Attributes
+					for key, want := range map[string]string{
+						"project_id":            "p",
+						"name":                  "existing",
+						"description":           "created elsewhere",
+						"wait_for_availability": "true",
+					} {
+						if attrs[key] != want {
+							return fmt.Errorf("%s = %q, want %q", key, attrs[key], want)
+						}
+					}
+					return nil
+				},
+			},
+			{
+				Config: testProviderConfig(srv) + `
+resource "polaris_table" "existing" {
+  project_id  = "p"
+  name        = "existing"
+  type        = "detail"
+  description = "created elsewhere"
+}
+`,
+				PlanOnly: true,
+			},
+		},
+	})
+
+	if _, ok := srv.Table("p", "existing"); ok {
+		t.Error("table still exists after destroy")
+	}
+}
+
+func TestResourcePolarisTableRecreatedWhenDeletedOutsideTerraform(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	config := testProviderConfig(srv) + `
+resource "polaris_table" "events" {
+  project_id = "p"
+  name       = "events"
+  type       = "detail"
+}
+`
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					srv.InjectFault(polaristest.Fault{
+						Method:     http.MethodGet,
+						PathPrefix: "/v1/projects/p/tables/events",
+						StatusCode: http.StatusNotFound,
+						Count:      1,
+					})
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestResourcePolarisTableReportsEveryPlanError(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	// All three problems are reported in one plan. Terraform may wrap the
+	// message, so words are matched across any whitespace.
+	wantErr := regexp.MustCompile(`(?s)coarser\s+than\s+partitioning_granularity.*` +
+		`at\s+least\s+one\s+schema\s+column.*` +
+		`clustering\s+column\s+"city"\s+is\s+not\s+declared`)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testProviderConfig(srv) + `
+resource "polaris_table" "rollup" {
+  project_id               = "p"
+  name                     = "rollup"
+  type                     = "aggregate"
+  partitioning_granularity = "hour"
+  time_resolution          = "day"
+  clustering_columns       = ["city"]
+
+  schema {
+    name      = "country"
+    type      = "dimension"
+    data_type = "string"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: wantErr,
+			},
+		},
+	})
+
+	if got := srv.RequestCount(); got != 0 {
+		t.Errorf("server saw %d requests, want none for an invalid plan", got)
+	}
+}
+
+func TestSchemaRoundTrip(t *testing.T) {
+	columns := []SchemaColumn{
+		{Name: "__time", Type: "dimension", DataType: "timestamp"},
+		{Name: "country", Type: "dimension", DataType: "string", PrimaryKey: true},
+		{Name: "clicks", Type: "measure", DataType: "long", AggregateFunction: "longSum"},
+		{Name: "users", Type: "measure", DataType: "HLLSketch", AggregateFunction: "HLLSketch", LgK: 12, TgtHllType: "HLL_8"},
+		{Name: "sessions", Type: "measure", DataType: "thetaSketch", AggregateFunction: "thetaSketch", Size: 16384},
+		{Name: "latency", Type: "measure", DataType: "quantilesDoublesSketch", AggregateFunction: "quantilesDoublesSketch", K: 128},
+	}
+
+	if got := expandSchema(flattenSchema(columns)); !reflect.DeepEqual(got, columns) {
+		t.Errorf("expandSchema(flattenSchema(columns)) = %#v, want %#v", got, columns)
+	}
+
+	// Through state, where unset sketches read back as empty lists.
+	d := resourcePolarisTable().TestResourceData()
+	if err := d.Set("schema", flattenSchema(columns)); err != nil {
+		t.Fatalf("setting schema: %s", err)
+	}
+	if got := expandSchema(d.Get("schema").([]interface{})); !reflect.DeepEqual(got, columns) {
+		t.Errorf("schema read back from state = %#v, want %#v", got, columns)
+	}
+}
+
+func TestResourcePolarisTableStoragePolicy(t *testing.T) {
+	testAccPreCheck(t)
+
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	config := func(storagePolicy string) string {
+		return testProviderConfig(srv) + fmt.Sprintf(`
+resource "polaris_table" "events" {
+  project_id = "p"
+  name       = "events"
+  type       = "detail"
+%s
+}
+`, storagePolicy)
+	}
+	forever := map[string]interface{}{"type": "forever"}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`
+  storage_policy {
+    cached {
+      type   = "period"
+      period = "P30D"
+    }
+    retain {
+      type      = "intervals"
+      intervals = ["2024-01-01/2025-01-01"]
+    }
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_table.events", "storage_policy.0.cached.0.period", "P30D"),
+					resource.TestCheckResourceAttr("polaris_table.events", "storage_policy.0.retain.0.intervals.0", "2024-01-01/2025-01-01"),
+				),
+			},
+			{
+				// Removing a rule resets it to the default.
+				Config: config(`
+  storage_policy {
+    cached {
+      type   = "period"
+      period = "P30D"
+    }
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_table.events", "storage_policy.0.retain.#", "0"),
+					testCheckPolarisTableStoragePolicy(srv, "p", "events", "retain", forever),
+				),
+			},
+			{
+				// Removing the block resets the whole policy.
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_table.events", "storage_policy.#", "0"),
+					testCheckPolarisTableStoragePolicy(srv, "p", "events", "cached", forever),
+					testCheckPolarisTableStoragePolicy(srv, "p", "events", "retain", forever),
+				),
+			},
+			{
+				// Rules that are not configured are left as they were set
+				// outside Terraform.
+				PreConfig: func() {
+					table, _ := srv.Table("p", "events")
+					table["storagePolicy"] = map[string]interface{}{
+						"cached": forever,
+						"retain": map[string]interface{}{"type": "period", "period": "P90D"},
+					}
+					srv.PutTable("p", table)
+				},
+				Config: config(`  description = "Raw events"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("polaris_table.events", "storage_policy.#", "0"),
+					testCheckPolarisTableStoragePolicy(srv, "p", "events", "retain", map[string]interface{}{"type": "period", "period": "P90D"}),
+				),
+			},
+			{
+				// Declaring the default explicitly is stable too.
+				Config: config(`
+  storage_policy {
+    retain {
+      type = "forever"
+    }
+  }
+`),
+				Check: resource.TestCheckResourceAttr("polaris_table.events", "storage_policy.0.retain.0.type", "forever"),
+			},
+		},
+	})
+}
+
+func TestExpandStoragePolicyUpdate(t *testing.T) {
+	forever := &StoragePolicyDetail{Type: "forever"}
+	period := &StoragePolicyDetail{Type: "period", Period: "P30D"}
+	policy := func(rules ...string) []interface{} {
+		block := map[string]interface{}{"cached": []interface{}{}, "retain": []interface{}{}}
+		for _, rule := range rules {
+			block[rule] = []interface{}{map[string]interface{}{"type": "period", "period": "P30D", "intervals": []interface{}{}}}
+		}
+		return []interface{}{block}
+	}
+
+	tests := []struct {
+		name     string
+		old, new []interface{}
+		want     *StoragePolicy
+	}{
+		{"never configured", nil, nil, nil},
+		{"unchanged rule", policy("cached"), policy("cached"), &StoragePolicy{Cached: period}},
+		{"added rule", policy("cached"), policy("cached", "retain"), &StoragePolicy{Cached: period, Retain: period}},
+		{"removed rule", policy("cached", "retain"), policy("cached"), &StoragePolicy{Cached: period, Retain: forever}},
+		{"removed block", policy("cached", "retain"), nil, &StoragePolicy{Cached: forever, Retain: forever}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandStoragePolicyUpdate(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("storage policy = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenConfiguredStoragePolicy(t *testing.T) {
+	forever := &StoragePolicyDetail{Type: "forever"}
+	period := &StoragePolicyDetail{Type: "period", Period: "P30D"}
+	declared := func(rule string) []interface{} {
+		return []interface{}{map[string]interface{}{
+			rule: []interface{}{map[string]interface{}{"type": "forever"}},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		policy     *StoragePolicy
+		prior      []interface{}
+		imported   bool
+		wantCached bool
+		wantRetain bool
+	}{
+		{"no policy", nil, nil, false, false, false},
+		{"undeclared rules", &StoragePolicy{Cached: period, Retain: period}, nil, false, false, false},
+		{"declared rule", &StoragePolicy{Cached: period, Retain: period}, declared("retain"), false, false, true},
+		{"declared default", &StoragePolicy{Cached: forever, Retain: forever}, declared("retain"), false, false, true},
+		{"imported defaults", &StoragePolicy{Cached: forever, Retain: forever}, nil, true, false, false},
+		{"imported custom rule", &StoragePolicy{Cached: period, Retain: forever}, nil, true, true, false},
+		{"imported partial policy", &StoragePolicy{Retain: period}, nil, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flattenConfiguredStoragePolicy(tt.policy, tt.prior, tt.imported)
+			if !tt.wantCached && !tt.wantRetain {
+				if got != nil {
+					t.Errorf("storage_policy = %#v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("storage_policy = %#v, want one block", got)
+			}
+			block := got[0].(map[string]interface{})
+			if hasCached := len(block["cached"].([]interface{})) > 0; hasCached != tt.wantCached {
+				t.Errorf("cached = %#v, want set %t", block["cached"], tt.wantCached)
+			}
+			if hasRetain := len(block["retain"].([]interface{})) > 0; hasRetain != tt.wantRetain {
+				t.Errorf("retain = %#v, want set %t", block["retain"], tt.wantRetain)
+			}
+		})
+	}
+}
+
+// testCheckPolarisTable checks a field of the table as stored by the fake
+// Polaris API.
+func testCheckPolarisTable(srv *polaristest.Server, projectID, name, field string, want interface{}) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		table, ok := srv.Table(projectID, name)
+		if !ok {
+			return fmt.Errorf("table %s/%s does not exist", projectID, name)
+		}
+		if table[field] != want {
+			return fmt.Errorf("table %s/%s: %s = %#v, want %#v", projectID, name, field, table[field], want)
+		}
+		return nil
+	}
+}
+
+func testCheckPolarisTableDestroyed(srv *polaristest.Server, projectID, name string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if _, ok := srv.Table(projectID, name); ok {
+			return fmt.Errorf("table %s/%s still exists", projectID, name)
+		}
+		return nil
+	}
+}
+
+// testCheckPolarisTableStoragePolicy checks a rule of the storage policy of
+// the table as stored by the fake Polaris API.
+func testCheckPolarisTableStoragePolicy(srv *polaristest.Server, projectID, name, rule string, want map[string]interface{}) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		table, ok := srv.Table(projectID, name)
+		if !ok {
+			return fmt.Errorf("table %s/%s does not exist", projectID, name)
+		}
+		policy, _ := table["storagePolicy"].(map[string]interface{})
+		if got := policy[rule]; !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("table %s/%s: storagePolicy.%s = %#v, want %#v", projectID, name, rule, got, want)
+		}
+		return nil
+	}
+}
+
+// testPlanChange plans config against an existing resource whose state was
+// built from prior and returns the resulting diff.
+func testPlanChange(t *testing.T, r *schema.Resource, prior, config map[string]interface{}) *terraform.InstanceDiff {
+	t.Helper()
+	d := r.TestResourceData()
+	d.SetId("id")
+	for key, value := range prior {
+		if err := d.Set(key, value); err != nil {
+			t.Fatalf("setting %s: %s", key, err)
+		}
+	}
+	diff, err := r.Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatalf("Diff: %s", err)
+	}
+	return diff
+}
+
+func TestResourcePolarisTableReplacement(t *testing.T) {
+	prior := map[string]interface{}{
+		"project_id":               "p",
+		"name":                     "events",
+		"type":                     "detail",
+		"description":              "Raw events",
+		"partitioning_granularity": "day",
+		"clustering_columns":       []interface{}{"country"},
+		"schema": []interface{}{
+			testColumn("__time", "dimension", "timestamp"),
+			testColumn("country", "dimension", "string"),
+		},
+		"storage_policy": []interface{}{map[string]interface{}{
+			"retain": []interface{}{map[string]interface{}{"type": "forever"}},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		change      map[string]interface{}
+		requiresNew bool
+	}{
+		{"project_id", map[string]interface{}{"project_id": "other"}, true},
+		{"name", map[string]interface{}{"name": "clicks"}, true},
+		{"type", map[string]interface{}{"type": "aggregate", "schema": []interface{}{
+			testColumn("country", "dimension", "string"),
+			map[string]interface{}{"name": "clicks", "type": "measure", "data_type": "long", "aggregate_function": "longSum"},
+		}, "clustering_columns": []interface{}{}}, true},
+		{"description", map[string]interface{}{"description": "All events"}, false},
+		{"storage_policy", map[string]interface{}{"storage_policy": []interface{}{map[string]interface{}{
+			"retain": []interface{}{map[string]interface{}{"type": "period", "period": "P30D"}},
+		}}}, false},
+		{"schema", map[string]interface{}{"schema": []interface{}{
+			testColumn("__time", "dimension", "timestamp"),
+			testColumn("country", "dimension", "string"),
+			testColumn("city", "dimension", "string"),
+		}}, false},
+		{"partitioning_granularity", map[string]interface{}{"partitioning_granularity": "hour"}, false},
+		{"clustering_columns", map[string]interface{}{"clustering_columns": []interface{}{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{}
+			for key, value := range prior {
+				config[key] = value
+			}
+			for key, value := range tt.change {
+				config[key] = value
+			}
+
+			diff := testPlanChange(t, resourcePolarisTable(), prior, config)
+			if diff.Empty() {
+				t.Fatal("changing the configuration planned no diff")
+			}
+			if got := diff.RequiresNew(); got != tt.requiresNew {
+				t.Errorf("RequiresNew = %t, want %t; diff: %#v", got, tt.requiresNew, diff.Attributes)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	// Embedded so time zones validate on hosts without a zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// timeColumn is the primary timestamp column every Polaris table has.
+const timeColumn = "__time"
+
+// granularityOrder ranks the time granularities Polaris accepts from finest to
+// coarsest, so settings that depend on each other can be compared.
+var granularityOrder = []string{
+	"millisecond",
+	"second",
+	"minute",
+	"fifteen_minute",
+	"thirty_minute",
+	"hour",
+	"six_hour",
+	"day",
+	"week",
+	"month",
+	"quarter",
+	"year",
+	"all",
+}
+
+var (
+	tableTypes                = []string{"detail", "aggregate"}
+	partitioningGranularities = []string{"hour", "day", "week", "month", "year", "all"}
+	timeResolutions           = []string{"millisecond", "second", "minute", "hour", "day"}
+	columnTypes               = []string{"dimension", "measure"}
+	storagePolicyTypes        = []string{"forever", "period", "intervals"}
+	hllTargetTypes            = []string{"HLL_4", "HLL_6", "HLL_8"}
+)
+
+// queryGranularityTypes are the values of query_granularity.type: "none",
+// which keeps timestamps as ingested, a simple granularity, or "period".
+var queryGranularityTypes = []string{
+	"none",
+	"second",
+	"minute",
+	"fifteen_minute",
+	"thirty_minute",
+	"hour",
+	"six_hour",
+	"day",
+	"week",
+	"month",
+	"quarter",
+	"year",
+	"all",
+	"period",
+}
+
+// isoPeriodPattern matches ISO-8601 periods such as "PT15M" or "P1DT12H". A
+// period needs at least one component, so "P", "PT" and "P1DT" do not match.
+var isoPeriodPattern = regexp.MustCompile(`^P(` + isoPeriodDate + `(` + isoPeriodTime + `)?|` + isoPeriodTime + `)$`)
+
+const (
+	// isoPeriodDate matches the non-empty date part of a period, e.g. "1Y2M".
+	isoPeriodDate = `(\d+Y(\d+M)?(\d+W)?(\d+D)?|\d+M(\d+W)?(\d+D)?|\d+W(\d+D)?|\d+D)`
+	// isoPeriodTime matches the non-empty time part of a period, e.g. "T1H30M".
+	isoPeriodTime = `T(\d+H(\d+M)?(\d+(\.\d+)?S)?|\d+M(\d+(\.\d+)?S)?|\d+(\.\d+)?S)`
+)
+
+// aggregateFunctions are the aggregators a measure of an aggregate table can
+// be rolled up with.
+var aggregateFunctions = []string{
+	"count",
+	"longSum",
+	"longMin",
+	"longMax",
+	"doubleSum",
+	"doubleMin",
+	"doubleMax",
+	"floatSum",
+	"floatMin",
+	"floatMax",
+	"earliestByTime",
+	"latestByTime",
+	"HLLSketch",
+	"thetaSketch",
+	"quantilesDoublesSketch",
+}
+
+// resourcePolarisTableCustomizeDiff checks a table definition for mistakes
+// Polaris would otherwise only report at apply time. Every problem found is
+// returned, not just the first. Values that are unknown during the plan are
+// skipped.
+func resourcePolarisTableCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	var errs []error
+
+	tableType := d.Get("type").(string)
+	if d.NewValueKnown("type") && !slices.Contains(tableTypes, tableType) {
+		errs = append(errs, fmt.Errorf("type must be one of %q, got %q", tableTypes, tableType))
+	}
+
+	errs = append(errs, validateTableGranularities(d)...)
+	if d.NewValueKnown("storage_policy") {
+		errs = append(errs, validateStoragePolicy(d)...)
+	}
+	if d.NewValueKnown("schema") {
+		errs = append(errs, validateTableSchema(d, tableType)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateTableGranularities(d *schema.ResourceDiff) []error {
+	var errs []error
+
+	partitioning := d.Get("partitioning_granularity").(string)
+	partitioningKnown := d.NewValueKnown("partitioning_granularity") && partitioning != ""
+	if partitioningKnown && !slices.Contains(partitioningGranularities, partitioning) {
+		errs = append(errs, fmt.Errorf("partitioning_granularity must be one of %q, got %q", partitioningGranularities, partitioning))
+		partitioningKnown = false
+	}
+
+	resolution := d.Get("time_resolution").(string)
+	resolutionKnown := d.NewValueKnown("time_resolution") && resolution != ""
+	if resolutionKnown && !slices.Contains(timeResolutions, resolution) {
+		errs = append(errs, fmt.Errorf("time_resolution must be one of %q, got %q", timeResolutions, resolution))
+		resolutionKnown = false
+	}
+
+	if partitioningKnown && resolutionKnown && granularityRank(resolution) > granularityRank(partitioning) {
+		errs = append(errs, fmt.Errorf("time_resolution %q is coarser than partitioning_granularity %q", resolution, partitioning))
+	}
+
+	if !d.NewValueKnown("query_granularity") {
+		return errs
+	}
+	qg := expandQueryGranularity(d.Get("query_granularity").([]interface{}))
+	if qg == nil || qg.Type == "" {
+		return errs
+	}
+	if qg.Type == "period" {
+		if qg.Period == "" {
+			errs = append(errs, fmt.Errorf("query_granularity period is required when type is \"period\""))
+		}
+		return errs
+	}
+	if qg.Period != "" || qg.TimeZone != "" || qg.Origin != "" {
+		errs = append(errs, fmt.Errorf("query_granularity period, time_zone and origin are only valid when type is \"period\""))
+	}
+	simple := qg.Type
+	if simple == "none" {
+		simple = "millisecond"
+	}
+	if resolutionKnown && granularityRank(simple) >= 0 && granularityRank(simple) < granularityRank(resolution) {
+		errs = append(errs, fmt.Errorf("query_granularity %q is finer than time_resolution %q", qg.Type, resolution))
+	}
+	return errs
+}
+
+func validateStoragePolicy(d *schema.ResourceDiff) []error {
+	sp := expandStoragePolicy(d.Get("storage_policy").([]interface{}))
+	if sp == nil {
+		return nil
+	}
+
+	var errs []error
+	rules := []struct {
+		name string
+		rule *StoragePolicyDetail
+	}{{"cached", sp.Cached}, {"retain", sp.Retain}}
+	for _, r := range rules {
+		name, rule := r.name, r.rule
+		if rule == nil {
+			continue
+		}
+		switch rule.Type {
+		case "forever":
+			if rule.Period != "" || len(rule.Intervals) > 0 {
+				errs = append(errs, fmt.Errorf("storage_policy %s: period and intervals cannot be set when type is \"forever\"", name))
+			}
+		case "period":
+			if rule.Period == "" {
+				errs = append(errs, fmt.Errorf("storage_policy %s: period is required when type is \"period\"", name))
+			}
+			if len(rule.Intervals) > 0 {
+				errs = append(errs, fmt.Errorf("storage_policy %s: intervals cannot be set when type is \"period\"", name))
+			}
+		case "intervals":
+			if len(rule.Intervals) == 0 {
+				errs = append(errs, fmt.Errorf("storage_policy %s: intervals are required when type is \"intervals\"", name))
+			}
+			if rule.Period != "" {
+				errs = append(errs, fmt.Errorf("storage_policy %s: period cannot be set when type is \"intervals\"", name))
+			}
+		}
+	}
+	return errs
+}
+
+// validateISOInterval checks that a string attribute holds an ISO-8601
+// interval: "start/end", "start/period" or "period/end", where start and end
+// are dates or RFC 3339 timestamps.
+func validateISOInterval(v interface{}, k string) (ws []string, errs []error) {
+	value := v.(string)
+	start, end, ok := strings.Cut(value, "/")
+	if !ok {
+		errs = append(errs, fmt.Errorf("%q must be an ISO-8601 interval such as \"2024-01-01/2025-01-01\", got %q", k, value))
+		return
+	}
+
+	startTime, startErr := parseIntervalTime(start)
+	endTime, endErr := parseIntervalTime(end)
+	switch {
+	case startErr == nil && endErr == nil:
+		if !startTime.Before(endTime) {
+			errs = append(errs, fmt.Errorf("%q must start before it ends, got %q", k, value))
+		}
+	case startErr == nil && isoPeriodPattern.MatchString(end):
+	case endErr == nil && isoPeriodPattern.MatchString(start):
+	default:
+		errs = append(errs, fmt.Errorf("%q must be an ISO-8601 interval of two timestamps, or a timestamp and a period, got %q", k, value))
+	}
+	return
+}
+
+// validateTimeZone checks that a string attribute holds an IANA time zone name
+// such as "America/New_York" or "UTC".
+func validateTimeZone(v interface{}, k string) (ws []string, errs []error) {
+	value := v.(string)
+	// LoadLocation also accepts "Local", the zone of whichever host runs
+	// Terraform, which means nothing to Polaris.
+	if _, err := time.LoadLocation(value); err != nil || value == "Local" {
+		errs = append(errs, fmt.Errorf("%q must be an IANA time zone such as \"America/New_York\", got %q", k, value))
+	}
+	return
+}
+
+func parseIntervalTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date or timestamp", value)
+}
+
+func validateTableSchema(d *schema.ResourceDiff, tableType string) []error {
+	var errs []error
+
+	columns := expandSchema(d.Get("schema").([]interface{}))
+	declared := make(map[string]SchemaColumn, len(columns))
+	hasMeasure := false
+	// Names and types are required, so empty values are ones that are not
+	// known until apply.
+	namesKnown, typesKnown := true, true
+	for _, column := range columns {
+		if column.Type == "" {
+			typesKnown = false
+		}
+		if column.Name == "" {
+			namesKnown = false
+			continue
+		}
+		if _, ok := declared[column.Name]; ok {
+			errs = append(errs, fmt.Errorf("schema column %q is declared more than once", column.Name))
+		}
+		declared[column.Name] = column
+		errs = append(errs, validateTableColumn(column, tableType)...)
+
+		if column.Type == "measure" {
+			hasMeasure = true
+			if column.PrimaryKey {
+				errs = append(errs, fmt.Errorf("schema column %q is a measure and cannot be part of the primary key", column.Name))
+			}
+		}
+	}
+
+	if tableType == "aggregate" && typesKnown && !hasMeasure {
+		errs = append(errs, fmt.Errorf("aggregate tables must declare at least one schema column of type \"measure\""))
+	}
+
+	if column, ok := declared[timeColumn]; ok {
+		if column.DataType != "" && column.DataType != "timestamp" {
+			errs = append(errs, fmt.Errorf("schema column %q must have data_type \"timestamp\", got %q", timeColumn, column.DataType))
+		}
+		if column.Type == "measure" {
+			errs = append(errs, fmt.Errorf("schema column %q cannot be a measure", timeColumn))
+		}
+	}
+
+	if d.NewValueKnown("clustering_columns") {
+		for _, raw := range d.Get("clustering_columns").([]interface{}) {
+			name, _ := raw.(string)
+			if name == "" {
+				continue
+			}
+			if name == timeColumn {
+				errs = append(errs, fmt.Errorf("clustering_columns cannot include %q, tables are always partitioned by time", timeColumn))
+				continue
+			}
+			column, ok := declared[name]
+			if !ok && namesKnown {
+				errs = append(errs, fmt.Errorf("clustering column %q is not declared in schema", name))
+			} else if column.Type == "measure" {
+				errs = append(errs, fmt.Errorf("clustering column %q is a measure, only dimensions can be clustered", name))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateTableColumn checks the kind, aggregate function and sketch
+// parameters of a single schema column.
+func validateTableColumn(column SchemaColumn, tableType string) []error {
+	var errs []error
+
+	if column.Type != "" && !slices.Contains(columnTypes, column.Type) {
+		errs = append(errs, fmt.Errorf("schema column %q: type must be one of %q, got %q", column.Name, columnTypes, column.Type))
+	}
+
+	switch {
+	case column.AggregateFunction == "":
+		if column.Type == "measure" && tableType == "aggregate" {
+			errs = append(errs, fmt.Errorf("schema column %q: measures of aggregate tables require an aggregate_function", column.Name))
+		}
+	case column.Type == "dimension":
+		errs = append(errs, fmt.Errorf("schema column %q: aggregate_function is only valid on measures", column.Name))
+	case !slices.Contains(aggregateFunctions, column.AggregateFunction):
+		errs = append(errs, fmt.Errorf("schema column %q: aggregate_function must be one of %q, got %q", column.Name, aggregateFunctions, column.AggregateFunction))
+	}
+
+	hll := column.LgK != 0 || column.TgtHllType != ""
+	if hll && column.AggregateFunction != "HLLSketch" {
+		errs = append(errs, fmt.Errorf("schema column %q: sketch lg_k and tgt_hll_type are only valid with aggregate_function \"HLLSketch\"", column.Name))
+	}
+	if column.LgK != 0 && (column.LgK < 4 || column.LgK > 21) {
+		errs = append(errs, fmt.Errorf("schema column %q: sketch lg_k must be between 4 and 21, got %d", column.Name, column.LgK))
+	}
+	if column.TgtHllType != "" && !slices.Contains(hllTargetTypes, column.TgtHllType) {
+		errs = append(errs, fmt.Errorf("schema column %q: sketch tgt_hll_type must be one of %q, got %q", column.Name, hllTargetTypes, column.TgtHllType))
+	}
+
+	if column.Size != 0 {
+		if column.AggregateFunction != "thetaSketch" {
+			errs = append(errs, fmt.Errorf("schema column %q: sketch size is only valid with aggregate_function \"thetaSketch\"", column.Name))
+		}
+		if !isPowerOfTwo(column.Size) || column.Size < 16 || column.Size > 1<<26 {
+			errs = append(errs, fmt.Errorf("schema column %q: sketch size must be a power of 2 between 16 and %d, got %d", column.Name, 1<<26, column.Size))
+		}
+	}
+
+	if column.K != 0 {
+		if column.AggregateFunction != "quantilesDoublesSketch" {
+			errs = append(errs, fmt.Errorf("schema column %q: sketch k is only valid with aggregate_function \"quantilesDoublesSketch\"", column.Name))
+		}
+		if !isPowerOfTwo(column.K) || column.K < 2 || column.K > 1<<15 {
+			errs = append(errs, fmt.Errorf("schema column %q: sketch k must be a power of 2 between 2 and %d, got %d", column.Name, 1<<15, column.K))
+		}
+	}
+
+	return errs
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func granularityRank(granularity string) int {
+	return slices.Index(granularityOrder, granularity)
+}
@@ -0,0 +1,310 @@
+package polaris
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestISOPeriodPattern(t *testing.T) {
+	valid := []string{"P1D", "PT15M", "P1DT12H", "P1Y2M3W4D", "PT1H30M", "PT0.5S", "P2W", "PT1M30S"}
+	for _, period := range valid {
+		if !isoPeriodPattern.MatchString(period) {
+			t.Errorf("isoPeriodPattern rejects %q", period)
+		}
+	}
+
+	invalid := []string{"", "P", "PT", "P1DT", "1D", "P1H", "PT1D", "P1D2Y", "PT1S1M", "P1.5D"}
+	for _, period := range invalid {
+		if isoPeriodPattern.MatchString(period) {
+			t.Errorf("isoPeriodPattern accepts %q", period)
+		}
+	}
+}
+
+func TestValidateISOInterval(t *testing.T) {
+	valid := []string{"2024-01-01/2025-01-01", "2024-01-01T00:00:00Z/P1M", "P1D/2025-01-01"}
+	for _, interval := range valid {
+		if _, errs := validateISOInterval(interval, "intervals"); len(errs) > 0 {
+			t.Errorf("validateISOInterval(%q): %v", interval, errs)
+		}
+	}
+
+	invalid := []string{"2024-01-01", "2025-01-01/2024-01-01", "2024-01-01/P", "PT/2025-01-01", "P1D/P2D"}
+	for _, interval := range invalid {
+		if _, errs := validateISOInterval(interval, "intervals"); len(errs) == 0 {
+			t.Errorf("validateISOInterval(%q) reported no error", interval)
+		}
+	}
+}
+
+func TestValidateTimeZone(t *testing.T) {
+	for _, zone := range []string{"UTC", "America/New_York", "Europe/Berlin", "Asia/Kolkata"} {
+		if _, errs := validateTimeZone(zone, "time_zone"); len(errs) > 0 {
+			t.Errorf("validateTimeZone(%q): %v", zone, errs)
+		}
+	}
+	for _, zone := range []string{"Local", "Mars/Olympus", "EST5", "+05:00"} {
+		if _, errs := validateTimeZone(zone, "time_zone"); len(errs) == 0 {
+			t.Errorf("validateTimeZone(%q) reported no error", zone)
+		}
+	}
+}
+
+// testUnknownValue marks a configuration value as unknown until apply, the
+// way Terraform passes references to resources not yet created.
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// testTableDiff plans a new polaris_table from config and returns the error
+// reported by its CustomizeDiff.
+func testTableDiff(config map[string]interface{}) error {
+	raw := map[string]interface{}{
+		"project_id": "p",
+		"name":       "events",
+		"type":       "detail",
+	}
+	for key, value := range config {
+		raw[key] = value
+	}
+	_, err := resourcePolarisTable().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil)
+	return err
+}
+
+func testColumn(name, columnType, dataType string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "type": columnType, "data_type": dataType}
+}
+
+func TestResourcePolarisTableCustomizeDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		// wantErrs are substrings of the errors expected, all of which
+		// must be reported; none means the plan is valid.
+		wantErrs []string
+	}{
+		{
+			name: "valid detail table",
+			config: map[string]interface{}{
+				"clustering_columns": []interface{}{"country"},
+				"schema": []interface{}{
+					testColumn("__time", "dimension", "timestamp"),
+					testColumn("country", "dimension", "string"),
+				},
+			},
+		},
+		{
+			name:     "unknown table type",
+			config:   map[string]interface{}{"type": "rollup"},
+			wantErrs: []string{`type must be one of`},
+		},
+		{
+			name: "aggregate table without a measure",
+			config: map[string]interface{}{
+				"type":   "aggregate",
+				"schema": []interface{}{testColumn("country", "dimension", "string")},
+			},
+			wantErrs: []string{`aggregate tables must declare at least one schema column of type "measure"`},
+		},
+		{
+			name: "aggregate table with a measure",
+			config: map[string]interface{}{
+				"type": "aggregate",
+				"schema": []interface{}{
+					testColumn("country", "dimension", "string"),
+					map[string]interface{}{"name": "clicks", "type": "measure", "data_type": "long", "aggregate_function": "longSum"},
+				},
+			},
+		},
+		{
+			name: "undeclared clustering column",
+			config: map[string]interface{}{
+				"clustering_columns": []interface{}{"city"},
+				"schema":             []interface{}{testColumn("country", "dimension", "string")},
+			},
+			wantErrs: []string{`clustering column "city" is not declared in schema`},
+		},
+		{
+			name: "measure clustering column",
+			config: map[string]interface{}{
+				"clustering_columns": []interface{}{"clicks"},
+				"schema":             []interface{}{testColumn("clicks", "measure", "long")},
+			},
+			wantErrs: []string{`clustering column "clicks" is a measure`},
+		},
+		{
+			name:     "clustering on __time",
+			config:   map[string]interface{}{"clustering_columns": []interface{}{"__time"}},
+			wantErrs: []string{`clustering_columns cannot include "__time"`},
+		},
+		{
+			name: "resolution coarser than partitioning",
+			config: map[string]interface{}{
+				"partitioning_granularity": "hour",
+				"time_resolution":          "day",
+			},
+			wantErrs: []string{`time_resolution "day" is coarser than partitioning_granularity "hour"`},
+		},
+		{
+			name: "query granularity finer than resolution",
+			config: map[string]interface{}{
+				"time_resolution":   "minute",
+				"query_granularity": []interface{}{map[string]interface{}{"type": "second"}},
+			},
+			wantErrs: []string{`query_granularity "second" is finer than time_resolution "minute"`},
+		},
+		{
+			name: "period query granularity without a period",
+			config: map[string]interface{}{
+				"query_granularity": []interface{}{map[string]interface{}{"type": "period"}},
+			},
+			wantErrs: []string{`query_granularity period is required`},
+		},
+		{
+			name:     "__time with the wrong data type",
+			config:   map[string]interface{}{"schema": []interface{}{testColumn("__time", "dimension", "string")}},
+			wantErrs: []string{`schema column "__time" must have data_type "timestamp", got "string"`},
+		},
+		{
+			name:     "__time as a measure",
+			config:   map[string]interface{}{"schema": []interface{}{testColumn("__time", "measure", "timestamp")}},
+			wantErrs: []string{`schema column "__time" cannot be a measure`},
+		},
+		{
+			name: "duplicate columns",
+			config: map[string]interface{}{
+				"schema": []interface{}{
+					testColumn("country", "dimension", "string"),
+					testColumn("country", "dimension", "string"),
+				},
+			},
+			wantErrs: []string{`schema column "country" is declared more than once`},
+		},
+		{
+			name: "measure in the primary key",
+			config: map[string]interface{}{
+				"schema": []interface{}{
+					map[string]interface{}{"name": "clicks", "type": "measure", "data_type": "long", "primary_key": true},
+				},
+			},
+			wantErrs: []string{`schema column "clicks" is a measure and cannot be part of the primary key`},
+		},
+		{
+			name: "storage policy period without a period",
+			config: map[string]interface{}{
+				"storage_policy": []interface{}{map[string]interface{}{
+					"retain": []interface{}{map[string]interface{}{"type": "period"}},
+				}},
+			},
+			wantErrs: []string{`storage_policy retain: period is required`},
+		},
+		{
+			name: "several problems at once",
+			config: map[string]interface{}{
+				"type":                     "aggregate",
+				"partitioning_granularity": "hour",
+				"time_resolution":          "day",
+				"clustering_columns":       []interface{}{"city"},
+				"schema":                   []interface{}{testColumn("country", "dimension", "string")},
+			},
+			wantErrs: []string{
+				`is coarser than partitioning_granularity`,
+				`aggregate tables must declare at least one schema column`,
+				`clustering column "city" is not declared`,
+			},
+		},
+		{
+			name: "unknown values are skipped",
+			config: map[string]interface{}{
+				"type":                     "aggregate",
+				"partitioning_granularity": "hour",
+				"time_resolution":          testUnknownValue,
+				"clustering_columns":       []interface{}{"city"},
+				"schema": []interface{}{
+					testColumn(testUnknownValue, "dimension", "string"),
+					testColumn("clicks", testUnknownValue, "long"),
+				},
+			},
+		},
+		{
+			name: "unknown schema is skipped",
+			config: map[string]interface{}{
+				"type":               "aggregate",
+				"clustering_columns": []interface{}{"city"},
+				"schema":             testUnknownValue,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testTableDiff(tt.config)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTableColumn(t *testing.T) {
+	measure := func(aggregateFunction string) SchemaColumn {
+		return SchemaColumn{Name: "m", Type: "measure", DataType: "long", AggregateFunction: aggregateFunction}
+	}
+	withSketch := func(c SchemaColumn, set func(*SchemaColumn)) SchemaColumn {
+		set(&c)
+		return c
+	}
+
+	tests := []struct {
+		name      string
+		column    SchemaColumn
+		tableType string
+		wantErrs  []string
+	}{
+		{"dimension", SchemaColumn{Name: "d", Type: "dimension", DataType: "string"}, "aggregate", nil},
+		{"unknown column type", SchemaColumn{Name: "d", Type: "metric"}, "detail", []string{"type must be one of"}},
+		{"measure of a detail table", measure(""), "detail", nil},
+		{"measure of an aggregate table", measure(""), "aggregate", []string{"require an aggregate_function"}},
+		{"aggregate_function on a dimension", SchemaColumn{Name: "d", Type: "dimension", AggregateFunction: "longSum"}, "aggregate", []string{"only valid on measures"}},
+		{"unknown aggregate_function", measure("median"), "aggregate", []string{"aggregate_function must be one of"}},
+		{"HLLSketch", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.LgK, c.TgtHllType = 12, "HLL_8" }), "aggregate", nil},
+		{"lg_k minimum", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.LgK = 4 }), "aggregate", nil},
+		{"lg_k maximum", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.LgK = 21 }), "aggregate", nil},
+		{"lg_k too small", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.LgK = 3 }), "aggregate", []string{"lg_k must be between 4 and 21"}},
+		{"lg_k too large", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.LgK = 22 }), "aggregate", []string{"lg_k must be between 4 and 21"}},
+		{"unknown tgt_hll_type", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.TgtHllType = "HLL_5" }), "aggregate", []string{"tgt_hll_type must be one of"}},
+		{"lg_k without HLLSketch", withSketch(measure("thetaSketch"), func(c *SchemaColumn) { c.LgK = 12 }), "aggregate", []string{`only valid with aggregate_function "HLLSketch"`}},
+		{"thetaSketch", withSketch(measure("thetaSketch"), func(c *SchemaColumn) { c.Size = 16384 }), "aggregate", nil},
+		{"size not a power of two", withSketch(measure("thetaSketch"), func(c *SchemaColumn) { c.Size = 1000 }), "aggregate", []string{"size must be a power of 2"}},
+		{"size too small", withSketch(measure("thetaSketch"), func(c *SchemaColumn) { c.Size = 8 }), "aggregate", []string{"size must be a power of 2"}},
+		{"size without thetaSketch", withSketch(measure("HLLSketch"), func(c *SchemaColumn) { c.Size = 16384 }), "aggregate", []string{`only valid with aggregate_function "thetaSketch"`}},
+		{"quantilesDoublesSketch", withSketch(measure("quantilesDoublesSketch"), func(c *SchemaColumn) { c.K = 128 }), "aggregate", nil},
+		{"k not a power of two", withSketch(measure("quantilesDoublesSketch"), func(c *SchemaColumn) { c.K = 100 }), "aggregate", []string{"k must be a power of 2"}},
+		{"k too large", withSketch(measure("quantilesDoublesSketch"), func(c *SchemaColumn) { c.K = 1 << 16 }), "aggregate", []string{"k must be a power of 2"}},
+		{"k without quantilesDoublesSketch", withSketch(measure("longSum"), func(c *SchemaColumn) { c.K = 128 }), "aggregate", []string{`only valid with aggregate_function "quantilesDoublesSketch"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateTableColumn(tt.column, tt.tableType)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("got errors %v, want %d matching %q", errs, len(tt.wantErrs), tt.wantErrs)
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %q does not contain %q", errs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+const (
+	// tableWaitMinInterval is the shortest time between two polls of a table
+	// the provider is waiting on. StateChangeConf backs off from there.
+	tableWaitMinInterval = 2 * time.Second
+
+	// tableDefaultTimeout is how long table operations wait by default.
+	tableDefaultTimeout = 20 * time.Minute
+
+	tableStatePending = "pending"
+	tableStateDeleted = "deleted"
+)
+
+// tableTerminalAvailabilities are the availabilities a table does not leave on
+// its own. Waiting for another availability stops as soon as one is seen.
+var tableTerminalAvailabilities = []string{"failed", "error", "deleting"}
+
+// waitForTableAvailability polls the table until its availability is desired
+// or timeout expires. Each poll is logged so progress shows up in the
+// Terraform logs; on timeout the error names the last availability observed.
+// A table that is briefly not found, as can happen right after it is created,
+// is still pending, while a terminal availability fails the wait at once.
+func waitForTableAvailability(ctx context.Context, client *Client, projectID, name, desired string, timeout time.Duration) (*Table, error) {
+	start := time.Now()
+	lastAvailability := "unknown"
+
+	conf := &retry.StateChangeConf{
+		Pending:    []string{tableStatePending},
+		Target:     []string{desired},
+		Timeout:    timeout,
+		MinTimeout: tableWaitMinInterval,
+		Refresh: func() (interface{}, string, error) {
+			table, err := client.GetTable(ctx, projectID, name)
+			if IsNotFound(err) {
+				lastAvailability = "not found"
+				tflog.Info(ctx, "Waiting for table to be visible", map[string]interface{}{
+					"project_id": projectID,
+					"name":       name,
+					"elapsed":    time.Since(start).Round(time.Second).String(),
+				})
+				return name, tableStatePending, nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			lastAvailability = table.Availability
+			tflog.Info(ctx, "Waiting for table availability", map[string]interface{}{
+				"project_id":   projectID,
+				"name":         name,
+				"availability": table.Availability,
+				"desired":      desired,
+				"elapsed":      time.Since(start).Round(time.Second).String(),
+			})
+			if table.Availability == desired {
+				return table, desired, nil
+			}
+			if slices.Contains(tableTerminalAvailabilities, table.Availability) {
+				return nil, "", fmt.Errorf("table availability is %s, which it will not leave", table.Availability)
+			}
+			return table, tableStatePending, nil
+		},
+	}
+
+	result, err := conf.WaitForStateContext(ctx)
+	var timeoutErr *retry.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return nil, fmt.Errorf("timed out after %s waiting for table %s to become %s, last observed availability: %s", timeout, name, desired, lastAvailability)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for table %s to become %s (last observed availability: %s): %w", name, desired, lastAvailability, err)
+	}
+	return result.(*Table), nil
+}
+
+// waitForTableDeletion polls the table until Polaris no longer returns it or
+// timeout expires.
+func waitForTableDeletion(ctx context.Context, client *Client, projectID, name string, timeout time.Duration) error {
+	start := time.Now()
+	lastAvailability := "unknown"
+
+	conf := &retry.StateChangeConf{
+		Pending:    []string{tableStatePending},
+		Target:     []string{tableStateDeleted},
+		Timeout:    timeout,
+		MinTimeout: tableWaitMinInterval,
+		Refresh: func() (interface{}, string, error) {
+			table, err := client.GetTable(ctx, projectID, name)
+			if IsNotFound(err) {
+				return name, tableStateDeleted, nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			lastAvailability = table.Availability
+			tflog.Info(ctx, "Waiting for table deletion", map[string]interface{}{
+				"project_id":   projectID,
+				"name":         name,
+				"availability": table.Availability,
+				"elapsed":      time.Since(start).Round(time.Second).String(),
+			})
+			return table, tableStatePending, nil
+		},
+	}
+
+	_, err := conf.WaitForStateContext(ctx)
+	var timeoutErr *retry.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return fmt.Errorf("timed out after %s waiting for table %s to be deleted, last observed availability: %s", timeout, name, lastAvailability)
+	}
+	if err != nil {
+		return fmt.Errorf("error waiting for table %s to be deleted (last observed availability: %s): %w", name, lastAvailability, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package polaris
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mussa-shirazi-imply/terraform-provider-polaris/polaris/polaristest"
+)
+
+func TestWaitForTableAvailabilityTreatsNotFoundAsPending(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutTable("p", map[string]interface{}{"name": "events", "type": "detail"})
+	srv.InjectFault(polaristest.Fault{
+		Method:     http.MethodGet,
+		PathPrefix: "/v1/projects/p/tables/events",
+		StatusCode: http.StatusNotFound,
+		Count:      1,
+	})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	table, err := waitForTableAvailability(context.Background(), client, "p", "events", "available", time.Minute)
+	if err != nil {
+		t.Fatalf("waitForTableAvailability: %s", err)
+	}
+	if table.Availability != "available" {
+		t.Errorf("availability = %q, want available", table.Availability)
+	}
+}
+
+func TestWaitForTableAvailabilityStopsOnTerminalAvailability(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutTable("p", map[string]interface{}{"name": "events", "type": "detail", "availability": "failed"})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	start := time.Now()
+	_, err := waitForTableAvailability(context.Background(), client, "p", "events", "available", time.Minute)
+	if err == nil {
+		t.Fatal("waitForTableAvailability succeeded for a failed table")
+	}
+	if !strings.Contains(err.Error(), "last observed availability: failed") {
+		t.Errorf("error %q does not name the last availability", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("wait took %s, want it to stop at the first poll", elapsed)
+	}
+}
+
+func TestWaitForTableAvailabilityTimeout(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+	srv.PutTable("p", map[string]interface{}{"name": "events", "type": "detail", "availability": "unavailable"})
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	_, err := waitForTableAvailability(context.Background(), client, "p", "events", "available", time.Second)
+	if err == nil {
+		t.Fatal("waitForTableAvailability succeeded for an unavailable table")
+	}
+	if want := "timed out after 1s waiting for table events to become available, last observed availability: unavailable"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestWaitForTableDeletion(t *testing.T) {
+	srv := polaristest.NewServer()
+	defer srv.Close()
+
+	client := NewClient(srv.URL, NewAPIKeyAuthenticator("key"))
+	if err := waitForTableDeletion(context.Background(), client, "p", "events", time.Minute); err != nil {
+		t.Fatalf("waitForTableDeletion: %s", err)
+	}
+}
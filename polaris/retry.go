@@ -0,0 +1,134 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests that failed with a
+// transient error.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero
+	// disables retries.
+	MaxRetries int
+	// MinBackoff is the base delay before the first retry; it doubles on each
+	// subsequent retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between retries, including delays asked for
+	// by Retry-After.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 4,
+		MinBackoff: time.Second,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// backoff returns how long to wait before retrying after the given attempt
+// (zero-based). A Retry-After header on resp takes precedence over the
+// computed delay, but is still capped at MaxBackoff so that a server asking
+// for an hour does not stall the apply.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp, time.Now()); ok {
+		return min(wait, max(p.MaxBackoff, 0))
+	}
+
+	wait := p.MinBackoff
+	for i := 0; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half of the delay and randomise the rest so that
+	// parallel resource operations don't retry in lockstep.
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// retryAfter parses the Retry-After header of resp, which is either a number
+// of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// shouldRetry reports whether a request that failed with err may be sent
+// again. Idempotent methods are retried on throttling, server errors and
+// transport errors. POST is only retried when Polaris cannot have acted on
+// the request: on 429 and 503 responses, or when the connection was never
+// established.
+func shouldRetry(ctx context.Context, method string, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+
+	var authErr *authenticationError
+	if errors.As(err, &authErr) {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		if idempotent {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package polaris
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		got, ok := retryAfter(resp, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %t, want %s, %t", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		wait := policy.backoff(attempt, nil)
+		if wait < max/2 || wait > max {
+			t.Errorf("attempt %d: backoff %s, want between %s and %s", attempt, wait, max/2, max)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"7"}}}
+	if wait := policy.backoff(0, resp); wait != 4*time.Second {
+		t.Errorf("backoff with Retry-After: 7 = %s, want 4s", wait)
+	}
+
+	resp = &http.Response{Header: http.Header{"Retry-After": {"3"}}}
+	if wait := policy.backoff(0, resp); wait != 3*time.Second {
+		t.Errorf("backoff with Retry-After: 3 = %s, want 3s", wait)
+	}
+
+	resp = &http.Response{Header: http.Header{"Retry-After": {"3600"}}}
+	if wait := policy.backoff(0, resp); wait != policy.MaxBackoff {
+		t.Errorf("backoff with Retry-After: 3600 = %s, want MaxBackoff %s", wait, policy.MaxBackoff)
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	apiErr := func(status int) error { return &APIError{StatusCode: status} }
+
+	tests := []struct {
+		method string
+		err    error
+		want   bool
+	}{
+		{http.MethodGet, nil, false},
+		{http.MethodGet, dialErr, true},
+		{http.MethodGet, readErr, true},
+		{http.MethodGet, apiErr(http.StatusTooManyRequests), true},
+		{http.MethodGet, apiErr(http.StatusInternalServerError), true},
+		{http.MethodGet, apiErr(http.StatusNotFound), false},
+		{http.MethodGet, &authenticationError{err: errors.New("invalid_client")}, false},
+		{http.MethodDelete, apiErr(http.StatusBadGateway), true},
+		{http.MethodPost, dialErr, true},
+		{http.MethodPost, readErr, false},
+		{http.MethodPost, errors.New("unexpected EOF"), false},
+		{http.MethodPost, apiErr(http.StatusTooManyRequests), true},
+		{http.MethodPost, apiErr(http.StatusServiceUnavailable), true},
+		{http.MethodPost, apiErr(http.StatusInternalServerError), false},
+		{http.MethodPost, apiErr(http.StatusGatewayTimeout), false},
+		{http.MethodPatch, dialErr, true},
+		{http.MethodPatch, apiErr(http.StatusBadGateway), false},
+	}
+	for _, tt := range tests {
+		if got := shouldRetry(context.Background(), tt.method, tt.err); got != tt.want {
+			t.Errorf("shouldRetry(%s, %v) = %t, want %t", tt.method, tt.err, got, tt.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if shouldRetry(ctx, http.MethodGet, dialErr) {
+		t.Error("shouldRetry retries after the context was cancelled")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type Table struct {
@@ -36,20 +37,44 @@ type StoragePolicy struct {
 	Retain *StoragePolicyDetail `json:"retain,omitempty"`
 }
 
+// StoragePolicyDetail is a caching or retention rule. Type is "forever",
+// "period", which keeps the most recent ISO-8601 Period of data, or
+// "intervals", which keeps the data in the listed ISO-8601 Intervals.
 type StoragePolicyDetail struct {
 	Type      string   `json:"type"`
+	Period    string   `json:"period,omitempty"`
 	Intervals []string `json:"intervals,omitempty"`
 }
 
+// defaultStoragePolicyDetail is the rule Polaris applies when a table's
+// policy does not set one: keep all data.
+var defaultStoragePolicyDetail = StoragePolicyDetail{Type: "forever"}
+
+// QueryGranularity is either a simple granularity such as "hour", where Type
+// names the granularity, or a period granularity with Type "period" and an
+// ISO-8601 Period, optionally aligned to TimeZone and Origin.
 type QueryGranularity struct {
-	Type string `json:"type"`
+	Type     string `json:"type"`
+	Period   string `json:"period,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+	Origin   string `json:"origin,omitempty"`
 }
 
+// SchemaColumn is a column of a table schema. Type is the column kind,
+// "dimension" or "measure". Measures of aggregate tables are rolled up with
+// AggregateFunction, and sketch aggregators take the optional sketch
+// parameters: LgK and TgtHllType for HLLSketch, Size for thetaSketch and K for
+// quantilesDoublesSketch.
 type SchemaColumn struct {
-	Name       string `json:"name"`
-	Type       string `json:"type"`
-	DataType   string `json:"dataType"`
-	PrimaryKey bool   `json:"primaryKey,omitempty"`
+	Name              string `json:"name"`
+	Type              string `json:"type"`
+	DataType          string `json:"dataType"`
+	PrimaryKey        bool   `json:"primaryKey,omitempty"`
+	AggregateFunction string `json:"aggregateFunction,omitempty"`
+	LgK               int    `json:"lgK,omitempty"`
+	TgtHllType        string `json:"tgtHllType,omitempty"`
+	Size              int    `json:"size,omitempty"`
+	K                 int    `json:"k,omitempty"`
 }
 
 type User struct {
@@ -76,24 +101,47 @@ type InnerError struct {
 	Message string `json:"message"`
 }
 
-func parseErrorResponse(response *http.Response) string {
-	var errorResponse ErrorResponse
-	if err := json.NewDecoder(response.Body).Decode(&errorResponse); err != nil {
-		return "Unknown error"
+// parseErrorResponse builds an APIError from a non-2xx response. Bodies that
+// are not an ErrorResponse are kept verbatim as the error message.
+func parseErrorResponse(method, path string, statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: statusCode,
 	}
 
-	errorMessage := fmt.Sprintf("Error: %s - %s", errorResponse.Code, errorResponse.Message)
-
-	if len(errorResponse.Details) > 0 {
-		errorMessage += "\nDetails:"
-		for _, detail := range errorResponse.Details {
-			errorMessage += fmt.Sprintf("\n - %s: %s", detail.Code, detail.Message)
+	var errorResponse ErrorResponse
+	if err := json.Unmarshal(body, &errorResponse); err != nil || (errorResponse.Code == "" && errorResponse.Message == "") {
+		apiErr.Message = strings.TrimSpace(string(body))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(statusCode)
 		}
+		return apiErr
 	}
 
-	if errorResponse.InnerError.Code != "" {
-		errorMessage += fmt.Sprintf("\nInner Error: %s - %s", errorResponse.InnerError.Code, errorResponse.InnerError.Message)
+	apiErr.Code = errorResponse.Code
+	apiErr.Message = errorResponse.Message
+	apiErr.Target = errorResponse.Target
+	apiErr.Details = errorResponse.Details
+	if errorResponse.InnerError.Code != "" || errorResponse.InnerError.Message != "" {
+		innerError := errorResponse.InnerError
+		apiErr.InnerError = &innerError
 	}
+	return apiErr
+}
+
+// compositeID joins a project ID and a name into the "project_id/name" form
+// used for resource IDs.
+func compositeID(projectID, name string) string {
+	return projectID + "/" + name
+}
 
-	return errorMessage
+// parseCompositeID splits an ID of the form "project_id/name". format is shown
+// to the user when the ID is malformed.
+func parseCompositeID(id, format string) (string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected ID %q, expected %s", id, format)
+	}
+	return parts[0], parts[1], nil
 }
@@ -0,0 +1,64 @@
+package polaris
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseErrorResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   *APIError
+	}{
+		{
+			name:   "error response",
+			status: http.StatusBadRequest,
+			body:   `{"code": "BadArgument", "message": "invalid table", "target": "schema", "details": [{"code": "Invalid", "message": "bad type", "target": "schema[0].dataType"}], "innererror": {"code": "E1", "message": "inner"}}`,
+			want: &APIError{
+				StatusCode: http.StatusBadRequest,
+				Code:       "BadArgument",
+				Message:    "invalid table",
+				Target:     "schema",
+				Details:    []ErrorResponseDetail{{Code: "Invalid", Message: "bad type", Target: "schema[0].dataType"}},
+				InnerError: &InnerError{Code: "E1", Message: "inner"},
+			},
+		},
+		{
+			name:   "without inner error",
+			status: http.StatusNotFound,
+			body:   `{"code": "NotFound", "message": "no such table"}`,
+			want:   &APIError{StatusCode: http.StatusNotFound, Code: "NotFound", Message: "no such table"},
+		},
+		{
+			name:   "text body",
+			status: http.StatusBadGateway,
+			body:   "  upstream unavailable\n",
+			want:   &APIError{StatusCode: http.StatusBadGateway, Message: "upstream unavailable"},
+		},
+		{
+			name:   "JSON without code or message",
+			status: http.StatusConflict,
+			body:   `{"error": "exists"}`,
+			want:   &APIError{StatusCode: http.StatusConflict, Message: `{"error": "exists"}`},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusServiceUnavailable,
+			body:   "",
+			want:   &APIError{StatusCode: http.StatusServiceUnavailable, Message: "Service Unavailable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Method = http.MethodGet
+			tt.want.Path = "/v1/projects/p/tables/events"
+			got := parseErrorResponse(http.MethodGet, "/v1/projects/p/tables/events", tt.status, []byte(tt.body))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseErrorResponse = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}